@@ -0,0 +1,38 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package fileutil_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/internal/fileutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileutil")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+
+	assert.NoError(t, fileutil.WriteFileAtomic(path, []byte(`{"a":1}`)))
+	assert.NoError(t, fileutil.WriteFileAtomic(path, []byte(`{"b":2}`)))
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"b":2}`, string(b))
+
+	// No temporary file is left behind.
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	t.Run("With missing directory", func(t *testing.T) {
+		assert.Error(t, fileutil.WriteFileAtomic(filepath.Join(dir, "missing", "store.json"), nil))
+	})
+}
@@ -4,6 +4,8 @@
 
 package owerr
 
+import "errors"
+
 // Error OneWay specific error.
 // Switch based on code to handle specific error when using OneWayClient.
 type Error interface {
@@ -22,7 +24,47 @@ type Error interface {
 
 // New initializes a new OneWayError.
 func New(code, message string, statusCode int) Error {
-	return newBaseError(code, message, statusCode)
+	return newAPIError(code, message, statusCode, nil)
+}
+
+// Wrap initializes a new OneWayError caused by the error provided, such as a network or parse error.
+// The cause is returned by errors.Unwrap.
+func Wrap(err error, code, message string, statusCode int) Error {
+	return newAPIError(code, message, statusCode, err)
+}
+
+// NewWithDetails initializes a new OneWayError carrying the gateway details provided, to be quoted in support requests.
+// The body is truncated to MaxBodyLength bytes. err is the underlying error, if any.
+func NewWithDetails(err error, code, message string, statusCode int, details Details) Error {
+	e := newAPIError(code, message, statusCode, err)
+	details.Body = truncate(details.Body)
+	e.details = details
+	return e
+}
+
+// MaxBodyLength maximum length in bytes of the raw response body kept in errors, longer bodies being truncated.
+const MaxBodyLength = 256
+
+// Details gateway details of an error.
+type Details struct {
+	Path        string // Path of the gateway endpoint requested, such as /api.aspx.
+	Body        string // Raw response body, truncated to MaxBodyLength bytes.
+	GatewayCode int    // Numeric code returned by the gateway, such as -600. 0 when the gateway returned none.
+	MTID        int    // Mobile terminating ID the request is about. 0 when none.
+}
+
+// ValidationError OneWay input validation error, returned before any request is made.
+// The error code is always InvalidParameter and the status code is always 0.
+type ValidationError interface {
+	Error
+
+	// Returns the name of the input field failing validation.
+	Field() string
+}
+
+// NewValidationError initializes a new ValidationError for the field of the named input structure.
+func NewValidationError(input, field, message string) ValidationError {
+	return newValidationError(input, field, message)
 }
 
 const (
@@ -53,6 +95,59 @@ const (
 	// MessageDeliveryFailure message delivery failure error. Error is thrown when Message has been failed to deliver when calling check transaction status API.
 	MessageDeliveryFailure = "MessageDeliveryFailure"
 
+	// InvalidParameter invalid Parameter error. Error is thrown when an input value is invalid, before any request is made.
+	InvalidParameter = "InvalidParameter"
+
+	// RecipientSuppressed recipient Suppressed error. Error is thrown when a recipient is in the client's suppression list and is not sent to.
+	RecipientSuppressed = "RecipientSuppressed"
+
+	// RateLimited rate Limited error. Error is thrown when the client's rate limiter does not allow a request to be made in time.
+	RateLimited = "RateLimited"
+
 	// UnknownError unknown error. Unknown Response returned from OneWay API Gateway.
 	UnknownError = "UnknownError"
 )
+
+// Sentinel errors of each code, matching any error with the same code with errors.Is. For instance:
+//
+//	if errors.Is(err, owerr.ErrInsufficientCreditBalance) {
+//		// Handle InsufficientCreditBalance
+//	}
+var (
+	ErrRequestFailure            = newAPIError(RequestFailure, "request failure", 0, nil)
+	ErrInvalidCredentials        = newAPIError(InvalidCredentials, "apiusername or apipassword is invalid", 0, nil)
+	ErrInvalidSenderID           = newAPIError(InvalidSenderID, "senderid parameter is invalid", 0, nil)
+	ErrInvalidMobileNo           = newAPIError(InvalidMobileNo, "mobileno parameter is invalid", 0, nil)
+	ErrInvalidLanguageType       = newAPIError(InvalidLanguageType, "languagetype is invalid", 0, nil)
+	ErrInvalidMessageCharacters  = newAPIError(InvalidMessageCharacters, "characters in message are invalid", 0, nil)
+	ErrInsufficientCreditBalance = newAPIError(InsufficientCreditBalance, "insufficient credit balance", 0, nil)
+	ErrMTInvalidNotFound         = newAPIError(MTInvalidNotFound, "mtid is invalid or not found", 0, nil)
+	ErrMessageDeliveryFailure    = newAPIError(MessageDeliveryFailure, "message delivery failed", 0, nil)
+	ErrInvalidParameter          = newAPIError(InvalidParameter, "invalid parameter", 0, nil)
+	ErrRecipientSuppressed       = newAPIError(RecipientSuppressed, "recipient is suppressed", 0, nil)
+	ErrRateLimited               = newAPIError(RateLimited, "rate limit exceeded", 0, nil)
+	ErrUnknownError              = newAPIError(UnknownError, "unknown error", 0, nil)
+)
+
+// Permanent reports whether the error is a permanent failure which sending again will not fix, namely an Error
+// with the InvalidCredentials, InvalidSenderID, InvalidMobileNo, InvalidLanguageType, InvalidMessageCharacters,
+// InvalidParameter or RecipientSuppressed code. Other errors, such as network errors or an insufficient
+// credit balance, are transient.
+func Permanent(err error) bool {
+	var owErr Error
+	if !errors.As(err, &owErr) {
+		return false
+	}
+	switch owErr.Code() {
+	case InvalidCredentials,
+		InvalidSenderID,
+		InvalidMobileNo,
+		InvalidLanguageType,
+		InvalidMessageCharacters,
+		InvalidParameter,
+		RecipientSuppressed:
+		return true
+	default:
+		return false
+	}
+}
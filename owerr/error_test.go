@@ -0,0 +1,108 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owerr_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Run("With sentinel errors", func(t *testing.T) {
+		err := owerr.New(owerr.InsufficientCreditBalance, "insufficient credit balance", http.StatusOK)
+		assert.True(t, errors.Is(err, owerr.ErrInsufficientCreditBalance))
+		assert.False(t, errors.Is(err, owerr.ErrUnknownError))
+
+		wrapped := fmt.Errorf("sending reminder: %w", err)
+		assert.True(t, errors.Is(wrapped, owerr.ErrInsufficientCreditBalance))
+	})
+
+	t.Run("With errors.As", func(t *testing.T) {
+		err := fmt.Errorf("sending reminder: %w", owerr.New(owerr.InvalidMobileNo, "mobileno parameter is invalid", http.StatusOK))
+
+		var apiErr *owerr.APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, owerr.InvalidMobileNo, apiErr.Code())
+		assert.Equal(t, http.StatusOK, apiErr.StatusCode())
+
+		var owErr owerr.Error
+		assert.True(t, errors.As(err, &owErr))
+		assert.Equal(t, owerr.InvalidMobileNo, owErr.Code())
+	})
+
+	t.Run("With cause", func(t *testing.T) {
+		err := owerr.Wrap(io.ErrUnexpectedEOF, owerr.RequestFailure, "failed to read response", http.StatusOK)
+		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): failed to read response: unexpected EOF")
+		assert.Equal(t, io.ErrUnexpectedEOF, errors.Unwrap(err))
+		assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+		assert.True(t, errors.Is(err, owerr.ErrRequestFailure))
+	})
+
+	t.Run("Without status code", func(t *testing.T) {
+		err := owerr.NewWithDetails(io.EOF, owerr.RequestFailure, "request failure", 0, owerr.Details{Path: "/api.aspx"})
+		assert.EqualError(t, err, "OneWaySMS: Error: request failure (path /api.aspx): EOF")
+	})
+
+	t.Run("With validation error", func(t *testing.T) {
+		err := owerr.NewValidationError("SendSMSInput", "MobileNo", "MobileNo is required")
+		assert.EqualError(t, err, "SendSMSInput: Error: MobileNo is required")
+		assert.True(t, errors.Is(err, owerr.ErrInvalidParameter))
+
+		var apiErr *owerr.APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, owerr.InvalidParameter, apiErr.Code())
+
+		var vErr owerr.ValidationError
+		assert.True(t, errors.As(err, &vErr))
+		assert.Equal(t, "MobileNo", vErr.Field())
+	})
+
+	t.Run("With details", func(t *testing.T) {
+		err := owerr.NewWithDetails(nil, owerr.UnknownError, "unknown error", http.StatusOK, owerr.Details{
+			Path:        "/api.aspx",
+			Body:        "-999\r\n",
+			GatewayCode: -999,
+			MTID:        145712470,
+		})
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): unknown error (path /api.aspx, gateway code -999, mtid 145712470, body "-999")`)
+	})
+
+	t.Run("With credentials", func(t *testing.T) {
+		err := owerr.NewWithDetails(
+			errors.New(`Get "https://gateway.onewaysms.com.my/api.aspx?apiusername=user&apipassword=s3cret&senderid=Shop": EOF`),
+			owerr.RequestFailure, "request failure", 0,
+			owerr.Details{Path: "/api.aspx", Body: "apipassword=s3cret"},
+		)
+		assert.NotContains(t, err.Error(), "s3cret")
+		assert.NotContains(t, err.Error(), "user&")
+		assert.Contains(t, err.Error(), "apiusername=REDACTED&apipassword=REDACTED&senderid=Shop")
+	})
+
+	t.Run("With long body", func(t *testing.T) {
+		body := strings.Repeat("a", owerr.MaxBodyLength-1) + "世界"
+		err := owerr.NewWithDetails(nil, owerr.UnknownError, "unknown error", http.StatusOK, owerr.Details{Body: body})
+
+		var apiErr *owerr.APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, strings.Repeat("a", owerr.MaxBodyLength-1)+"...", apiErr.Details().Body)
+	})
+}
+
+func TestPermanent(t *testing.T) {
+	assert.True(t, owerr.Permanent(owerr.New(owerr.InvalidMobileNo, "mobileno parameter is invalid", http.StatusOK)))
+	assert.True(t, owerr.Permanent(owerr.NewValidationError("SendSMSInput", "MobileNo", "MobileNo is required")))
+	assert.True(t, owerr.Permanent(fmt.Errorf("send: %w", owerr.ErrRecipientSuppressed)))
+	assert.False(t, owerr.Permanent(owerr.New(owerr.RequestFailure, "request failure", http.StatusBadGateway)))
+	assert.False(t, owerr.Permanent(owerr.New(owerr.RateLimited, "rate limit exceeded", 0)))
+	assert.False(t, owerr.Permanent(owerr.New(owerr.InsufficientCreditBalance, "insufficient credit balance", http.StatusOK)))
+	assert.False(t, owerr.Permanent(errors.New("connection reset by peer")))
+}
@@ -7,39 +7,143 @@ package owerr
 import (
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
 )
 
-// OneWay base error.
-type baseError struct {
+// credentialsPattern matches the credential parameters of gateway URLs.
+var credentialsPattern = regexp.MustCompile(`(?i)(apiusername|apipassword)=[^&\s"']*`)
+
+// APIError OneWay error implementing Error. Errors returned by the client can be converted to it with errors.As,
+// and compared by code to the sentinel errors, such as ErrInsufficientCreditBalance, with errors.Is.
+type APIError struct {
 	code       string
 	message    string
 	statusCode int
+	details    Details
+	err        error
 }
 
-func newBaseError(code, message string, statusCode int) *baseError {
-	return &baseError{
+func newAPIError(code, message string, statusCode int, err error) *APIError {
+	return &APIError{
 		code:       code,
 		message:    message,
 		statusCode: statusCode,
+		err:        err,
 	}
 }
 
-// Error returns the string representation of the error.
-func (e *baseError) Error() string {
-	return fmt.Sprintf("OneWaySMS: Error %d (%s): %s", e.statusCode, http.StatusText(e.statusCode), e.message)
+// Error returns the string representation of the error, including its details and underlying error.
+// Credentials are redacted.
+func (e *APIError) Error() string {
+	s := "OneWaySMS: Error: " + e.message
+	if e.statusCode != 0 {
+		// Errors occurring before any response, such as network errors, have no status code.
+		s = fmt.Sprintf("OneWaySMS: Error %d (%s): %s", e.statusCode, http.StatusText(e.statusCode), e.message)
+	}
+
+	var details []string
+	if e.details.Path != "" {
+		details = append(details, "path "+e.details.Path)
+	}
+	if e.details.GatewayCode != 0 {
+		details = append(details, fmt.Sprintf("gateway code %d", e.details.GatewayCode))
+	}
+	if e.details.MTID != 0 {
+		details = append(details, fmt.Sprintf("mtid %d", e.details.MTID))
+	}
+	if body := strings.TrimSpace(e.details.Body); body != "" {
+		details = append(details, fmt.Sprintf("body %q", body))
+	}
+	if len(details) > 0 {
+		s += " (" + strings.Join(details, ", ") + ")"
+	}
+
+	if e.err != nil {
+		s += ": " + e.err.Error()
+	}
+	return redact(s)
+}
+
+// Details returns the gateway details of the error.
+func (e *APIError) Details() Details {
+	return e.details
 }
 
 // Message returns OneWay error message.
-func (e *baseError) Message() string {
+func (e *APIError) Message() string {
 	return e.message
 }
 
 // Code returns OneWay error code.
-func (e *baseError) Code() string {
+func (e *APIError) Code() string {
 	return e.code
 }
 
 // StatusCode returns OneWay error status code.
-func (e *baseError) StatusCode() int {
+func (e *APIError) StatusCode() int {
 	return e.statusCode
 }
+
+// Unwrap returns the underlying error, such as a network or parse error, if any.
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the target is an *APIError with the same code, so that errors.Is matches the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	return ok && t.code == e.code
+}
+
+// OneWay input validation error.
+type validationError struct {
+	*APIError
+	input string
+	field string
+}
+
+func newValidationError(input, field, message string) *validationError {
+	return &validationError{
+		APIError: newAPIError(InvalidParameter, message, 0, nil),
+		input:    input,
+		field:    field,
+	}
+}
+
+// Error returns the string representation of the error.
+func (e *validationError) Error() string {
+	return fmt.Sprintf("%s: Error: %s", e.input, e.message)
+}
+
+// Field returns the name of the input field failing validation.
+func (e *validationError) Field() string {
+	return e.field
+}
+
+// As sets the target to the underlying *APIError when the target is an **APIError, for errors.As.
+func (e *validationError) As(target interface{}) bool {
+	t, ok := target.(**APIError)
+	if ok {
+		*t = e.APIError
+	}
+	return ok
+}
+
+// redact redacts the credentials of gateway URLs found in s.
+func redact(s string) string {
+	return credentialsPattern.ReplaceAllString(s, "$1=REDACTED")
+}
+
+// truncate truncates the body to MaxBodyLength bytes, without splitting a UTF-8 encoded character.
+func truncate(body string) string {
+	if len(body) <= MaxBodyLength {
+		return body
+	}
+	n := MaxBodyLength
+	for n > 0 && !utf8.RuneStart(body[n]) {
+		n--
+	}
+	return body[:n] + "..."
+}
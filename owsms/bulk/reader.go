@@ -0,0 +1,143 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Column names of CSV files, and keys of JSONL objects.
+const (
+	ColumnMobileNo = "mobile_no"
+	ColumnMessage  = "message"
+	ColumnVars     = "vars"
+)
+
+// Row bulk job row structure.
+type Row struct {
+	Number   int                    // Row number, starting from 1. Data row number for CSV files, line number for JSONL files.
+	MobileNo string                 // Phone number of the recipient.
+	Message  string                 // Content of the SMS. When empty, the runner's template is rendered with Vars.
+	Vars     map[string]interface{} // Template variables of the row.
+	Err      error                  // Error of a malformed row, reported as the row's failure instead of being sent.
+}
+
+// Reader reads the rows of a bulk job. Read returns io.EOF once every row has been read.
+// Malformed rows are returned with their Err set, so that they do not stop the job.
+type Reader interface {
+	Read() (*Row, error)
+}
+
+// csvReader CSV rows reader.
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+	number int
+}
+
+// NewCSVReader initializes a new reader of CSV rows. The first record is the header and must have a mobile_no column,
+// while the message column is optional. Every other column is a template variable named after its header.
+func NewCSVReader(r io.Reader) (Reader, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("bulk: Error: CSV header is missing")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	hasMobileNo := false
+	for _, column := range header {
+		hasMobileNo = hasMobileNo || column == ColumnMobileNo
+	}
+	if !hasMobileNo {
+		return nil, errors.Errorf("bulk: Error: CSV header has no %s column", ColumnMobileNo)
+	}
+	return &csvReader{r: cr, header: header}, nil
+}
+
+// Read reads the next row.
+func (r *csvReader) Read() (*Row, error) {
+	record, err := r.r.Read()
+	if perr, ok := err.(*csv.ParseError); ok {
+		r.number++
+		return &Row{Number: r.number, Err: errors.Errorf("bulk: Error: row %d: %v", r.number, perr.Err)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.number++
+
+	row := &Row{Number: r.number, Vars: make(map[string]interface{})}
+	for i, column := range r.header {
+		switch column {
+		case ColumnMobileNo:
+			row.MobileNo = strings.TrimSpace(record[i])
+		case ColumnMessage:
+			row.Message = record[i]
+		default:
+			row.Vars[column] = record[i]
+		}
+	}
+	return row, nil
+}
+
+// jsonlReader JSON Lines rows reader.
+type jsonlReader struct {
+	s      *bufio.Scanner
+	number int
+}
+
+// NewJSONLReader initializes a new reader of JSON Lines rows. Each non-empty line is an object
+// with a mobile_no, and either a message or the template variables in a vars object.
+func NewJSONLReader(r io.Reader) Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &jsonlReader{s: s}
+}
+
+// Read reads the next row.
+func (r *jsonlReader) Read() (*Row, error) {
+	for r.s.Scan() {
+		r.number++
+
+		line := bytes.TrimSpace(r.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var v struct {
+			MobileNo string                 `json:"mobile_no"`
+			Message  string                 `json:"message"`
+			Vars     map[string]interface{} `json:"vars"`
+		}
+		if err := json.Unmarshal(line, &v); err != nil {
+			return &Row{Number: r.number, Err: errors.Errorf("bulk: Error: line %d: %v", r.number, err)}, nil
+		}
+		return &Row{
+			Number:   r.number,
+			MobileNo: strings.TrimSpace(v.MobileNo),
+			Message:  v.Message,
+			Vars:     v.Vars,
+		}, nil
+	}
+	if err := r.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,82 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package bulk_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/bulk"
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, r bulk.Reader) []*bulk.Row {
+	var rows []*bulk.Row
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return rows
+		}
+		assert.NoError(t, err)
+		if err != nil {
+			return rows
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	t.Run("With message and variables", func(t *testing.T) {
+		r, err := bulk.NewCSVReader(strings.NewReader("mobile_no,message,name\n60123456789,Hello World,Ali\n 60129876543 ,,Mei\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, []*bulk.Row{
+			{Number: 1, MobileNo: "60123456789", Message: "Hello World", Vars: map[string]interface{}{"name": "Ali"}},
+			{Number: 2, MobileNo: "60129876543", Vars: map[string]interface{}{"name": "Mei"}},
+		}, readAll(t, r))
+	})
+
+	t.Run("With malformed record", func(t *testing.T) {
+		r, err := bulk.NewCSVReader(strings.NewReader("mobile_no,message\n60123456789,Hello,World\n60129876543,Hello\n"))
+		assert.NoError(t, err)
+		rows := readAll(t, r)
+		assert.Len(t, rows, 2)
+		assert.EqualError(t, rows[0].Err, "bulk: Error: row 1: wrong number of fields")
+		assert.Equal(t, &bulk.Row{Number: 2, MobileNo: "60129876543", Message: "Hello", Vars: map[string]interface{}{}}, rows[1])
+	})
+
+	t.Run("With missing mobile_no column", func(t *testing.T) {
+		_, err := bulk.NewCSVReader(strings.NewReader("phone,message\n60123456789,Hello World\n"))
+		assert.EqualError(t, err, "bulk: Error: CSV header has no mobile_no column")
+	})
+
+	t.Run("With missing header", func(t *testing.T) {
+		_, err := bulk.NewCSVReader(strings.NewReader(""))
+		assert.EqualError(t, err, "bulk: Error: CSV header is missing")
+	})
+}
+
+func TestJSONLReader(t *testing.T) {
+	t.Run("With message and variables", func(t *testing.T) {
+		r := bulk.NewJSONLReader(strings.NewReader(`{"mobile_no": "60123456789", "message": "Hello World"}
+
+{"mobile_no": "60129876543", "vars": {"name": "Mei", "amount": 25}}
+`))
+		assert.Equal(t, []*bulk.Row{
+			{Number: 1, MobileNo: "60123456789", Message: "Hello World"},
+			{Number: 3, MobileNo: "60129876543", Vars: map[string]interface{}{"name": "Mei", "amount": float64(25)}},
+		}, readAll(t, r))
+	})
+
+	t.Run("With invalid line", func(t *testing.T) {
+		r := bulk.NewJSONLReader(strings.NewReader("{\"mobile_no\": \"60123456789\"}\n{invalid\n{\"mobile_no\": \"60129876543\"}\n"))
+		rows := readAll(t, r)
+		assert.Len(t, rows, 3)
+		assert.Equal(t, 2, rows[1].Number)
+		assert.Error(t, rows[1].Err)
+		assert.Contains(t, rows[1].Err.Error(), "bulk: Error: line 2")
+		assert.Equal(t, &bulk.Row{Number: 3, MobileNo: "60129876543"}, rows[2])
+	})
+}
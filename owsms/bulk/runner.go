@@ -0,0 +1,332 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package bulk sends SMS campaigns from CSV or JSON Lines files, writing the result of each row to a results file
+// and resuming from the completed rows of an existing results file after a crash.
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/pkg/errors"
+)
+
+// resultsHeader header of results files.
+var resultsHeader = []string{"row", "mobile_no", "mtid", "error"}
+
+// Sender sends SMS. *owsms.Client satisfies this interface.
+type Sender interface {
+	SendSMSWithContext(ctx context.Context, input *owsms.SendSMSInput) (*owsms.SendSMSOutput, *http.Response, error)
+}
+
+// Runner bulk job runner structure.
+type Runner struct {
+	Sender      Sender  // Sender of the SMS, usually an *owsms.Client.
+	Template    string  // Optional text/template of the SMS content, rendered with the variables of rows without a message.
+	Concurrency int     // Maximum number of rows sent concurrently. Defaults to 1.
+	Rate        float64 // Maximum number of rows sent per second. Zero means no limit.
+
+	// Prefix of the IdempotencyKey of each row, followed by "/" and the row number, so that a row sent before a crash
+	// but not yet written to the results is not sent twice on resume by a client with an idempotency store.
+	// RunFile defaults it to "bulk/" and the absolute path of the results file. Empty means no key.
+	KeyPrefix string
+}
+
+// Summary bulk job summary structure.
+type Summary struct {
+	Skipped   int // Number of rows skipped as already completed.
+	Sent      int // Number of rows sent successfully.
+	Failed    int // Number of rows which failed, whose error is written to the results.
+	Retryable int // Number of rows which failed transiently or were interrupted, left out of the results to be sent on resume.
+}
+
+// result bulk job row result structure.
+type result struct {
+	row      int
+	mobileNo string
+	mtID     int
+	err      string
+	retry    bool // Whether the row failed transiently, and must not be recorded as completed.
+}
+
+// Run sends the SMS of each row, writing the result of each row to results as a CSV record once the row completes.
+// Rows failing transiently, with an error which is not owerr.Permanent or because the context is done,
+// are not written, so that a resumed job sends them again. This includes RequestFailure errors of non-2xx responses,
+// such as 4xx, which are sent again on every resume. As the gateway may have accepted a row before its request failed,
+// for example on a timeout, such a row may be delivered twice. Rows sent but not yet written are only deduplicated
+// on resume with a KeyPrefix and a client with an idempotency store, see owsms.WithIdempotencyStore.
+// Rows whose number is in completed are skipped. The results header is only written when completed is nil.
+// Run stops reading rows when the context is done, waiting for in-flight rows before returning the context's error.
+func (r *Runner) Run(ctx context.Context, rows Reader, results io.Writer, completed map[int]bool) (*Summary, error) {
+	var tmpl *template.Template
+	if r.Template != "" {
+		var err error
+		tmpl, err = template.New("message").Option("missingkey=error").Parse(r.Template)
+		if err != nil {
+			return nil, errors.Wrap(err, "bulk: Error: template is invalid")
+		}
+	}
+
+	w := &resultWriter{w: csv.NewWriter(results)}
+	if completed == nil {
+		if err := w.write(resultsHeader); err != nil {
+			return nil, err
+		}
+	}
+
+	concurrency := r.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var ticker *time.Ticker
+	if r.Rate > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / r.Rate))
+		defer ticker.Stop()
+	}
+
+	var (
+		summary  = &Summary{}
+		mu       sync.Mutex
+		writeErr error
+		wg       sync.WaitGroup
+		queue    = make(chan *Row)
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range queue {
+				res := r.send(ctx, tmpl, row)
+
+				mu.Lock()
+				switch {
+				case res.retry:
+					summary.Retryable++
+					mu.Unlock()
+					continue
+				case res.err == "":
+					summary.Sent++
+				default:
+					summary.Failed++
+				}
+				if err := w.writeResult(res); err != nil && writeErr == nil {
+					writeErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	readErr := func() error {
+		defer close(queue)
+		for {
+			row, err := rows.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if completed[row.Number] {
+				summary.Skipped++
+				continue
+			}
+			if ticker != nil {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-ticker.C:
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case queue <- row:
+			}
+		}
+	}()
+	wg.Wait()
+
+	if readErr != nil {
+		return summary, readErr
+	}
+	return summary, writeErr
+}
+
+// RunFile runs the bulk job of the CSV or JSON Lines file at inputPath, detected by its .csv or .jsonl extension,
+// appending the result of each row to the CSV file at resultsPath. When the results file exists,
+// the rows it holds are skipped, resuming the job from where it stopped.
+func (r *Runner) RunFile(ctx context.Context, inputPath, resultsPath string) (*Summary, error) {
+	in, err := os.Open(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var rows Reader
+	switch strings.ToLower(filepath.Ext(inputPath)) {
+	case ".csv":
+		if rows, err = NewCSVReader(in); err != nil {
+			return nil, err
+		}
+	case ".jsonl", ".ndjson":
+		rows = NewJSONLReader(in)
+	default:
+		return nil, errors.Errorf("bulk: Error: unsupported input file extension %q", filepath.Ext(inputPath))
+	}
+
+	var completed map[int]bool
+	if b, err := ioutil.ReadFile(resultsPath); err == nil {
+		if completed, err = ReadCompleted(bytes.NewReader(b)); err != nil {
+			return nil, err
+		}
+		// Terminate a record partially written before a crash, so that the next one starts on its own line.
+		if len(b) > 0 && b[len(b)-1] != '\n' {
+			if err := appendFile(resultsPath, []byte("\n")); err != nil {
+				return nil, err
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	runner := *r
+	if runner.KeyPrefix == "" {
+		abs, err := filepath.Abs(resultsPath)
+		if err != nil {
+			return nil, err
+		}
+		runner.KeyPrefix = "bulk/" + abs
+	}
+
+	out, err := os.OpenFile(resultsPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := runner.Run(ctx, rows, out, completed)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return summary, err
+}
+
+// ReadCompleted reads the numbers of the completed rows from results written by Run.
+// Records partially written before a crash, and rows interrupted by a done context, are ignored.
+func ReadCompleted(results io.Reader) (map[int]bool, error) {
+	cr := csv.NewReader(results)
+	cr.FieldsPerRecord = -1
+
+	completed := make(map[int]bool)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return completed, nil
+		}
+		if _, ok := err.(*csv.ParseError); ok {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) != len(resultsHeader) {
+			continue
+		}
+		if e := record[3]; e == context.Canceled.Error() || e == context.DeadlineExceeded.Error() {
+			continue
+		}
+		if n, err := strconv.Atoi(record[0]); err == nil {
+			completed[n] = true
+		}
+	}
+}
+
+// send sends the SMS of the row.
+func (r *Runner) send(ctx context.Context, tmpl *template.Template, row *Row) result {
+	res := result{row: row.Number, mobileNo: row.MobileNo}
+	if row.Err != nil {
+		res.err = row.Err.Error()
+		return res
+	}
+
+	message := row.Message
+	if message == "" && tmpl != nil {
+		buf := new(bytes.Buffer)
+		if err := tmpl.Execute(buf, row.Vars); err != nil {
+			res.err = fmt.Sprintf("failed to render template: %v", err)
+			return res
+		}
+		message = buf.String()
+	}
+
+	input := &owsms.SendSMSInput{
+		Message:  message,
+		MobileNo: []string{row.MobileNo},
+	}
+	if r.KeyPrefix != "" {
+		input.IdempotencyKey = r.KeyPrefix + "/" + strconv.Itoa(row.Number)
+	}
+	output, _, err := r.Sender.SendSMSWithContext(ctx, input)
+	if err == nil && len(output.Results) > 0 {
+		err = output.Results[0].Err
+	}
+	switch {
+	case err != nil:
+		res.err = err.Error()
+		res.retry = ctx.Err() != nil || !owerr.Permanent(err)
+	case len(output.MTIDs) > 0:
+		res.mtID = output.MTIDs[0]
+	default:
+		res.err = "no mobile terminating ID returned"
+	}
+	return res
+}
+
+// resultWriter writes results as CSV records, flushing after every record so that completed rows survive a crash.
+type resultWriter struct {
+	w *csv.Writer
+}
+
+func (w *resultWriter) writeResult(res result) error {
+	mtID := ""
+	if res.mtID != 0 {
+		mtID = strconv.Itoa(res.mtID)
+	}
+	return w.write([]string{strconv.Itoa(res.row), res.mobileNo, mtID, res.err})
+}
+
+func (w *resultWriter) write(record []string) error {
+	if err := w.w.Write(record); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func appendFile(path string, b []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
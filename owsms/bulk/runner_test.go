@@ -0,0 +1,254 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package bulk_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/bulk"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSender returns an MTID derived from the last digits of the number, failing numbers ending with 0,
+// and failing numbers ending with 9 transiently.
+type fakeSender struct {
+	mu          sync.Mutex
+	messages    map[string]string
+	keys        map[string]string
+	inFlight    int32
+	maxInFlight int32
+	delay       time.Duration
+}
+
+func (s *fakeSender) SendSMSWithContext(ctx context.Context, input *owsms.SendSMSInput) (*owsms.SendSMSOutput, *http.Response, error) {
+	n := atomic.AddInt32(&s.inFlight, 1)
+	defer atomic.AddInt32(&s.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(&s.maxInFlight)
+		if n <= max || atomic.CompareAndSwapInt32(&s.maxInFlight, max, n) {
+			break
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-time.After(s.delay):
+	}
+
+	mobileNo := input.MobileNo[0]
+	s.mu.Lock()
+	if s.messages == nil {
+		s.messages = make(map[string]string)
+	}
+	s.messages[mobileNo] = input.Message
+	if s.keys == nil {
+		s.keys = make(map[string]string)
+	}
+	s.keys[mobileNo] = input.IdempotencyKey
+	s.mu.Unlock()
+
+	if strings.HasSuffix(mobileNo, "0") {
+		return nil, nil, owerr.New(owerr.InvalidMobileNo, "mobileno parameter is invalid", http.StatusOK)
+	}
+	if strings.HasSuffix(mobileNo, "9") {
+		return nil, nil, owerr.New(owerr.RequestFailure, "request failure", http.StatusBadGateway)
+	}
+	var mtID int
+	fmt.Sscanf(mobileNo[len(mobileNo)-3:], "%d", &mtID)
+	return &owsms.SendSMSOutput{
+		MTIDs:   []int{mtID},
+		Results: []owsms.SendSMSResult{{MobileNo: mobileNo, MTID: mtID}},
+	}, nil, nil
+}
+
+func TestRunner(t *testing.T) {
+	t.Run("With CSV rows", func(t *testing.T) {
+		sender := &fakeSender{}
+		runner := &bulk.Runner{Sender: sender, Template: "Hi {{.name}}"}
+
+		rows, err := bulk.NewCSVReader(strings.NewReader("mobile_no,message,name\n60120000001,Hello World,\n60120000002,,Mei\n60120000010,,Ali\n60120000003,,\n"))
+		assert.NoError(t, err)
+
+		results := new(bytes.Buffer)
+		summary, err := runner.Run(context.Background(), rows, results, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, &bulk.Summary{Sent: 3, Failed: 1}, summary)
+		assert.Equal(t, "Hello World", sender.messages["60120000001"])
+		assert.Equal(t, "Hi Mei", sender.messages["60120000002"])
+		assert.Equal(t, "Hi ", sender.messages["60120000003"])
+		assert.Empty(t, sender.keys["60120000001"])
+		assert.Equal(t, `row,mobile_no,mtid,error
+1,60120000001,1,
+2,60120000002,2,
+3,60120000010,,OneWaySMS: Error 200 (OK): mobileno parameter is invalid
+4,60120000003,3,
+`, results.String())
+	})
+
+	t.Run("With missing template variable", func(t *testing.T) {
+		sender := &fakeSender{}
+		runner := &bulk.Runner{Sender: sender, Template: "Hi {{.name}}"}
+
+		results := new(bytes.Buffer)
+		summary, err := runner.Run(context.Background(), bulk.NewJSONLReader(strings.NewReader(`{"mobile_no": "60120000001", "vars": {}}`)), results, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, &bulk.Summary{Failed: 1}, summary)
+		assert.Contains(t, results.String(), `1,60120000001,,"failed to render template`)
+		assert.Empty(t, sender.messages)
+	})
+
+	t.Run("With malformed row", func(t *testing.T) {
+		sender := &fakeSender{}
+		runner := &bulk.Runner{Sender: sender}
+
+		results := new(bytes.Buffer)
+		rows := bulk.NewJSONLReader(strings.NewReader("{invalid\n{\"mobile_no\": \"60120000002\", \"message\": \"Hello\"}"))
+		summary, err := runner.Run(context.Background(), rows, results, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, &bulk.Summary{Sent: 1, Failed: 1}, summary)
+		assert.Contains(t, results.String(), "\n1,,,bulk: Error: line 1: invalid character")
+		assert.Contains(t, results.String(), "\n2,60120000002,2,\n")
+		assert.Len(t, sender.messages, 1)
+	})
+
+	t.Run("With transient failure", func(t *testing.T) {
+		sender := &fakeSender{}
+		runner := &bulk.Runner{Sender: sender, KeyPrefix: "campaign"}
+
+		results := new(bytes.Buffer)
+		rows := bulk.NewJSONLReader(strings.NewReader("{\"mobile_no\": \"60120000009\", \"message\": \"Hello\"}\n{\"mobile_no\": \"60120000002\", \"message\": \"Hello\"}"))
+		summary, err := runner.Run(context.Background(), rows, results, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, &bulk.Summary{Sent: 1, Retryable: 1}, summary)
+		assert.Equal(t, "row,mobile_no,mtid,error\n2,60120000002,2,\n", results.String())
+		assert.Equal(t, map[string]string{"60120000009": "campaign/1", "60120000002": "campaign/2"}, sender.keys)
+
+		completed, err := bulk.ReadCompleted(results)
+		assert.NoError(t, err)
+		assert.Equal(t, map[int]bool{2: true}, completed)
+	})
+
+	t.Run("With concurrency and rate", func(t *testing.T) {
+		sender := &fakeSender{delay: 20 * time.Millisecond}
+		runner := &bulk.Runner{Sender: sender, Concurrency: 3, Rate: 200}
+
+		var lines []string
+		for i := 1; i <= 12; i++ {
+			lines = append(lines, fmt.Sprintf(`{"mobile_no": "601200%04d1", "message": "Hello"}`, i))
+		}
+
+		start := time.Now()
+		summary, err := runner.Run(context.Background(), bulk.NewJSONLReader(strings.NewReader(strings.Join(lines, "\n"))), ioutil.Discard, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 12, summary.Sent)
+		assert.True(t, time.Since(start) >= 55*time.Millisecond)
+		assert.True(t, atomic.LoadInt32(&sender.maxInFlight) <= 3)
+		assert.True(t, atomic.LoadInt32(&sender.maxInFlight) > 1)
+	})
+
+	t.Run("With cancelled context", func(t *testing.T) {
+		sender := &fakeSender{}
+		runner := &bulk.Runner{Sender: sender, Rate: 1}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		rows := bulk.NewJSONLReader(strings.NewReader("{\"mobile_no\": \"60120000001\", \"message\": \"Hello\"}\n{\"mobile_no\": \"60120000002\", \"message\": \"Hello\"}"))
+		_, err := runner.Run(ctx, rows, ioutil.Discard, nil)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Empty(t, sender.messages)
+	})
+
+	t.Run("With context done while sending", func(t *testing.T) {
+		sender := &fakeSender{delay: time.Second}
+		runner := &bulk.Runner{Sender: sender}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		results := new(bytes.Buffer)
+		rows := bulk.NewJSONLReader(strings.NewReader("{\"mobile_no\": \"60120000001\", \"message\": \"Hello\"}\n{\"mobile_no\": \"60120000002\", \"message\": \"Hello\"}"))
+		summary, err := runner.Run(ctx, rows, results, nil)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, &bulk.Summary{Retryable: 1}, summary)
+		assert.Equal(t, "row,mobile_no,mtid,error\n", results.String())
+
+		// Rows interrupted by an earlier release are resumed too.
+		completed, err := bulk.ReadCompleted(strings.NewReader("row,mobile_no,mtid,error\n1,60120000001,1,\n2,60120000002,,context canceled\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, map[int]bool{1: true}, completed)
+	})
+}
+
+func TestRunnerRunFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bulk")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "campaign.csv")
+	assert.NoError(t, ioutil.WriteFile(input, []byte("mobile_no,message\n60120000001,Hello\n60120000002,Hello\n60120000003,Hello\n60120000004,Hello\n"), 0644))
+
+	t.Run("With resume after crash", func(t *testing.T) {
+		results := filepath.Join(dir, "results.csv")
+		// Row 3 was partially written when the previous run crashed.
+		assert.NoError(t, ioutil.WriteFile(results, []byte("row,mobile_no,mtid,error\n1,60120000001,1,\n2,60120000002,2,\n3,6012"), 0644))
+
+		sender := &fakeSender{}
+		runner := &bulk.Runner{Sender: sender}
+
+		summary, err := runner.RunFile(context.Background(), input, results)
+		assert.NoError(t, err)
+		assert.Equal(t, &bulk.Summary{Skipped: 2, Sent: 2}, summary)
+		assert.Len(t, sender.messages, 2)
+
+		abs, err := filepath.Abs(results)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"60120000003": "bulk/" + abs + "/3",
+			"60120000004": "bulk/" + abs + "/4",
+		}, sender.keys)
+
+		b, err := ioutil.ReadFile(results)
+		assert.NoError(t, err)
+		assert.Equal(t, "row,mobile_no,mtid,error\n1,60120000001,1,\n2,60120000002,2,\n3,6012\n3,60120000003,3,\n4,60120000004,4,\n", string(b))
+
+		completed, err := bulk.ReadCompleted(bytes.NewReader(b))
+		assert.NoError(t, err)
+		assert.Equal(t, map[int]bool{1: true, 2: true, 3: true, 4: true}, completed)
+	})
+
+	t.Run("With new results file", func(t *testing.T) {
+		results := filepath.Join(dir, "new.csv")
+
+		summary, err := (&bulk.Runner{Sender: &fakeSender{}}).RunFile(context.Background(), input, results)
+		assert.NoError(t, err)
+		assert.Equal(t, &bulk.Summary{Sent: 4}, summary)
+
+		summary, err = (&bulk.Runner{Sender: &fakeSender{}}).RunFile(context.Background(), input, results)
+		assert.NoError(t, err)
+		assert.Equal(t, &bulk.Summary{Skipped: 4}, summary)
+	})
+
+	t.Run("With unsupported extension", func(t *testing.T) {
+		path := filepath.Join(dir, "campaign.txt")
+		assert.NoError(t, ioutil.WriteFile(path, nil, 0644))
+
+		_, err := (&bulk.Runner{Sender: &fakeSender{}}).RunFile(context.Background(), path, filepath.Join(dir, "txt.csv"))
+		assert.EqualError(t, err, `bulk: Error: unsupported input file extension ".txt"`)
+	})
+}
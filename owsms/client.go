@@ -5,71 +5,92 @@
 package owsms
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-	"unicode/utf8"
+	"sync"
+	"time"
 
 	"github.com/junwen-k/onewaysms-sdk-go/owerr"
 )
 
 const version = "0.1.0"
 
-// doer implements http.Client Do interface.
-type doer interface {
+// defaultLookupConcurrency default maximum number of concurrent lookups of CheckTransactionStatuses.
+const defaultLookupConcurrency = 4
+
+// defaultIdempotencyTTL default duration idempotency keys are kept for, see WithIdempotencyStore.
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// Doer implements http.Client Do interface.
+type Doer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
 // Client OneWaySMS client structure.
 // Based on specifications found in http://smsd2.onewaysms.sg/api.pdf.
 type Client struct {
-	client      doer
+	client      Doer
 	baseURL     string
 	apiUsername string
 	apiPassword string
 	senderID    string
-}
+	userAgent   string
+	timeout     time.Duration
+	logger      Logger
+	retryPolicy RetryPolicy
 
-// NewClient initializes a new OneWaySMS client.
-func NewClient(baseURL, apiUsername, apiPassword, senderID string) *Client {
-	return &Client{
-		client:      http.DefaultClient,
-		baseURL:     baseURL,
-		apiUsername: apiUsername,
-		apiPassword: apiPassword,
-		senderID:    senderID,
-	}
+	normalizePhone bool
+	phoneRegion    string
+
+	suppressionList SuppressionList
+
+	batchSize         int
+	batchConcurrency  int
+	lookupConcurrency int
+
+	sendLimiter  *RateLimiter
+	queryLimiter *RateLimiter
+
+	idempotencyStore IdempotencyStore
+	idempotencyTTL   time.Duration
+	idempotencyLocks keyedMutex
 }
 
-// NewClientWithHTTP initializes a new OneWaySMS client with custom http client.
-func NewClientWithHTTP(baseURL, apiUsername, apiPassword, senderID string, client doer) *Client {
-	c := NewClient(baseURL, apiUsername, apiPassword, senderID)
-	c.client = client
+// New initializes a new OneWaySMS client configured by the options provided.
+func New(opts ...Option) *Client {
+	c := &Client{
+		client:    http.DefaultClient,
+		userAgent: fmt.Sprintf("onewaysms-sdk-go/%s", version),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
 	return c
 }
 
-func (c *Client) messageToHex(message string) string {
-	buf := new(bytes.Buffer)
-	for _, r := range message {
-		buf.WriteString(strings.Trim(fmt.Sprintf("%U", r), "U+"))
-	}
-	return buf.String()
+// NewClient initializes a new OneWaySMS client.
+func NewClient(baseURL, apiUsername, apiPassword, senderID string) *Client {
+	return New(
+		WithBaseURL(baseURL),
+		WithCredentials(apiUsername, apiPassword),
+		WithSenderID(senderID),
+	)
 }
 
-func (c *Client) getLanguageType(message string) LanguageType {
-	m := message
-	for len(m) > 0 {
-		_, size := utf8.DecodeRuneInString(m)
-		if size > 1 {
-			return LanguageTypeUnicode
-		}
-		m = m[size:]
-	}
-	return LanguageTypeNormal
+// NewClientWithHTTP initializes a new OneWaySMS client with custom http client.
+func NewClientWithHTTP(baseURL, apiUsername, apiPassword, senderID string, client Doer) *Client {
+	return New(
+		WithBaseURL(baseURL),
+		WithCredentials(apiUsername, apiPassword),
+		WithSenderID(senderID),
+		WithHTTPClient(client),
+	)
 }
 
 func (c *Client) buildRequestURL(path string, urlParams map[string]string) string {
@@ -80,12 +101,14 @@ func (c *Client) buildRequestURL(path string, urlParams map[string]string) strin
 	return fmt.Sprintf("%s/%s?%s", c.baseURL, path, params.Encode())
 }
 
+// buildSendSMSRequestURL builds the send SMS request URL without modifying the input.
 func (c *Client) buildSendSMSRequestURL(input *SendSMSInput) string {
-	if input.LanguageType == "" {
-		input.LanguageType = c.getLanguageType(input.Message)
+	languageType, message := input.LanguageType, input.Message
+	if languageType == "" {
+		languageType = DetectLanguageType(message)
 	}
-	if input.LanguageType == LanguageTypeUnicode {
-		input.Message = c.messageToHex(input.Message)
+	if languageType == LanguageTypeUnicode {
+		message = EncodeUnicodeMessage(message)
 	}
 
 	return c.buildRequestURL("api.aspx", map[string]string{
@@ -93,8 +116,8 @@ func (c *Client) buildSendSMSRequestURL(input *SendSMSInput) string {
 		"apipassword":  c.apiPassword,
 		"senderid":     c.senderID,
 		"mobileno":     strings.Join(input.MobileNo, ","),
-		"languagetype": string(input.LanguageType),
-		"message":      input.Message,
+		"languagetype": string(languageType),
+		"message":      message,
 	})
 }
 
@@ -111,136 +134,515 @@ func (c *Client) buildCheckCreditBalanceRequestURL() string {
 	})
 }
 
-func (c *Client) getRequest(requestURL string) (*http.Response, error) {
+func (c *Client) getRequest(ctx context.Context, requestURL string) (*http.Response, error) {
 	if c.client == nil {
 		c.client = http.DefaultClient
 	}
 
-	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	cancel := context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", fmt.Sprintf("onewaysms-sdk-go/%s", version))
+	req.Header.Set("User-Agent", c.userAgent)
 
-	return c.client.Do(req)
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logf("owsms: GET %s failed after %s: %v", req.URL.Path, time.Since(start), redactURLError(err))
+		cancel()
+		return resp, err
+	}
+	c.logf("owsms: GET %s %d in %s", req.URL.Path, resp.StatusCode, time.Since(start))
+
+	// Release the timeout only once the caller is done reading the body.
+	resp.Body = &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// doRequest performs the request, retrying according to the client's retry policy.
+// Requests which are not idempotent are only retried when the connection could not be dialed.
+// Each attempt waits for the limiter, if any.
+func (c *Client) doRequest(ctx context.Context, requestURL string, idempotent bool, limiter *RateLimiter) (*http.Response, error) {
+	attempts := c.retryPolicy.maxAttempts()
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.getRequest(ctx, requestURL)
+		if attempt >= attempts || ctx.Err() != nil || !c.retryPolicy.shouldRetry(resp, err, idempotent) {
+			if err != nil {
+				err = redactURLError(err)
+			}
+			if err != nil && ctx.Err() == nil {
+				// Errors of a done context are only redacted, other transport errors are wrapped.
+				err = owerr.NewWithDetails(err, owerr.RequestFailure, "request failure", 0, owerr.Details{
+					Path: requestPath(requestURL),
+				})
+			}
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		backoff := c.retryPolicy.backoff(attempt)
+		c.logf("owsms: retrying attempt %d of %d in %s", attempt+1, attempts, backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// requestPath returns the path of the request URL, leaving out the query holding the credentials.
+func requestPath(requestURL string) string {
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// redactURLError redacts the credentials from the URL of transport errors, which include it in their message.
+func redactURLError(err error) error {
+	urlErr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	u, perr := url.Parse(urlErr.URL)
+	if perr != nil {
+		return &url.Error{Op: urlErr.Op, URL: "", Err: urlErr.Err}
+	}
+	query := u.Query()
+	for _, param := range []string{"apiusername", "apipassword"} {
+		if _, ok := query[param]; ok {
+			query.Set(param, "REDACTED")
+		}
+	}
+	u.RawQuery = query.Encode()
+	return &url.Error{Op: urlErr.Op, URL: u.String(), Err: urlErr.Err}
+}
+
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+	}
+}
+
+// cancelReadCloser cancels the request context when the body is closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelReadCloser) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
 }
 
 // SendSMS Initiate send SMS request. SMS's language type will be automatically set unless it is defined in the SMS request structure.
+// The input is validated before any request is made, see SendSMSInput.Validate.
+//
+// The output holds the result of each recipient, in the order of the input's MobileNo. Recipients rejected by the gateway
+// or before sending are reported with a per-recipient error, while an error is returned when the request fails as a whole,
+// along with the output reporting it as the error of each recipient sent to.
+// When every recipient is rejected, by the gateway or before sending, the error of the first is returned along with
+// the output.
+//
+// When the client has a batch size configured, see WithBatchSize, recipients are sent in batches and a batch failing
+// as a whole is reported as per-recipient errors. When every batch failed, the error of the first batch is returned too.
+// The returned *http.Response is then that of the first batch.
 func (c *Client) SendSMS(input *SendSMSInput) (*SendSMSOutput, *http.Response, error) {
+	return c.SendSMSWithContext(context.Background(), input)
+}
+
+// SendSMSWithContext same as SendSMS, with the request bound to the context provided.
+// Cancelling the context or exceeding its deadline aborts the in-flight request.
+//
+// When the input has an IdempotencyKey and the client has an idempotency store configured,
+// a repeated request with the same key returns the original output without calling the gateway, in which case
+// the returned *http.Response is nil and the output only holds the MTIDs. When some batches fail as a whole,
+// see WithBatchSize, the key is not stored and a repeated request only sends the batches which failed.
+func (c *Client) SendSMSWithContext(ctx context.Context, input *SendSMSInput) (*SendSMSOutput, *http.Response, error) {
+	if err := input.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	if input.IdempotencyKey == "" || c.idempotencyStore == nil {
+		return c.sendSMS(ctx, input)
+	}
+
+	c.idempotencyLocks.Lock(input.IdempotencyKey)
+	defer c.idempotencyLocks.Unlock(input.IdempotencyKey)
+
+	mtIDs, ok, err := c.idempotencyStore.Get(input.IdempotencyKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		return &SendSMSOutput{MTIDs: mtIDs}, nil, nil
+	}
+
+	return c.sendSMS(ctx, input)
+}
+
+func (c *Client) sendSMS(ctx context.Context, input *SendSMSInput) (*SendSMSOutput, *http.Response, error) {
+	idempotent := input.IdempotencyKey != "" && c.idempotencyStore != nil
+
+	plan, err := c.planRecipients(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(plan.mobileNo) <= 0 {
+		// Every recipient has been rejected before sending, the output reports the reason of each.
+		return plan.output(nil), nil, plan.results[0].Err
+	}
+
+	batches := splitMobileNo(plan.mobileNo, c.batchSize)
+	outcomes := make([]batchOutcome, len(batches))
+	if len(batches) == 1 {
+		// A single batch is either stored under the idempotency key itself or not sent at all.
+		outcomes[0] = c.sendSMSBatchOutcome(ctx, input, batches[0], false)
+	} else {
+		concurrency := c.batchConcurrency
+		if concurrency < 1 {
+			concurrency = 1
+		}
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for i, mobileNo := range batches {
+			wg.Add(1)
+			go func(i int, mobileNo []string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				outcomes[i] = c.sendSMSBatchOutcome(ctx, input, mobileNo, idempotent)
+			}(i, mobileNo)
+		}
+		wg.Wait()
+	}
+
+	failures := 0
+	results := make([]SendSMSResult, 0, len(plan.mobileNo))
+	for i, outcome := range outcomes {
+		if outcome.err == nil {
+			results = append(results, outcome.results...)
+			continue
+		}
+		// A batch failing as a whole only fails its own recipients.
+		failures++
+		for _, mobileNo := range batches[i] {
+			results = append(results, SendSMSResult{MobileNo: mobileNo, Err: outcome.err})
+		}
+	}
+	output := plan.output(results)
+	if failures == len(outcomes) {
+		// The output still reports recipients rejected before sending with their own error.
+		return output, outcomes[0].resp, outcomes[0].err
+	}
+	if len(output.MTIDs) <= 0 {
+		// Every recipient has been rejected, by the gateway or before sending.
+		return output, outcomes[0].resp, output.Results[0].Err
+	}
+
+	// The message has been sent, failing to store the key would only encourage the caller to send it again.
+	if idempotent && failures == 0 {
+		if err := c.idempotencyStore.Set(input.IdempotencyKey, output.MTIDs, c.idempotencyTTL); err != nil {
+			c.logf("owsms: failed to store idempotency key: %v", err)
+		}
+	}
+	if idempotent && failures > 0 {
+		// Remember the batches sent, so that a repeated request only sends the batches which failed.
+		for i, outcome := range outcomes {
+			if outcome.err != nil || outcome.cached {
+				continue
+			}
+			key := batchIdempotencyKey(input.IdempotencyKey, batches[i])
+			if err := c.idempotencyStore.Set(key, encodeBatchResults(outcome.results), c.idempotencyTTL); err != nil {
+				c.logf("owsms: failed to store idempotency key: %v", err)
+			}
+		}
+	}
+	return output, outcomes[0].resp, nil
+}
+
+// batchOutcome outcome of sending a batch of recipients.
+type batchOutcome struct {
+	results []SendSMSResult
+	resp    *http.Response
+	err     error
+	cached  bool // Whether the batch was sent by an earlier request with the same idempotency key.
+}
+
+// sendSMSBatchOutcome sends a batch of recipients. When idempotent, a batch sent by an earlier request with the same
+// idempotency key, which had other batches failing, is not sent again and its stored results are returned instead.
+func (c *Client) sendSMSBatchOutcome(ctx context.Context, input *SendSMSInput, mobileNo []string, idempotent bool) batchOutcome {
+	batch := *input
+	batch.MobileNo = mobileNo
+
+	if idempotent {
+		codes, ok, err := c.idempotencyStore.Get(batchIdempotencyKey(input.IdempotencyKey, mobileNo))
+		if err != nil {
+			return batchOutcome{err: err}
+		}
+		if ok && len(codes) == len(mobileNo) {
+			return batchOutcome{results: decodeBatchResults(mobileNo, codes), cached: true}
+		}
+	}
+
+	results, resp, err := c.sendSMSBatch(ctx, &batch)
+	return batchOutcome{results: results, resp: resp, err: err}
+}
+
+// splitMobileNo splits the numbers into batches of at most size numbers. A size below 1 means a single batch.
+func splitMobileNo(mobileNo []string, size int) [][]string {
+	if size < 1 || len(mobileNo) <= size {
+		return [][]string{mobileNo}
+	}
+	batches := make([][]string, 0, (len(mobileNo)+size-1)/size)
+	for len(mobileNo) > size {
+		batches = append(batches, mobileNo[:size:size])
+		mobileNo = mobileNo[size:]
+	}
+	return append(batches, mobileNo)
+}
+
+// sendSMSBatch sends the input to the gateway, returning the result of each of its recipients.
+// An error is returned when the request fails as a whole.
+func (c *Client) sendSMSBatch(ctx context.Context, input *SendSMSInput) ([]SendSMSResult, *http.Response, error) {
 	requestURL := c.buildSendSMSRequestURL(input)
 
-	resp, err := c.getRequest(requestURL)
+	resp, err := c.doRequest(ctx, requestURL, false, c.sendLimiter)
 	if err != nil {
 		return nil, resp, err
 	}
 	defer resp.Body.Close()
 
+	b, err := ioutil.ReadAll(resp.Body)
+	details := owerr.Details{Path: requestPath(requestURL), Body: string(b)}
 	if resp.StatusCode != http.StatusOK {
-		return nil, resp, owerr.New(owerr.RequestFailure, "request failure", resp.StatusCode)
+		return nil, resp, owerr.NewWithDetails(nil, owerr.RequestFailure, "request failure", resp.StatusCode, details)
 	}
-
-	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, resp, err
+		return nil, resp, owerr.NewWithDetails(err, owerr.RequestFailure, "failed to read response", resp.StatusCode, details)
 	}
 
-	mtIDs := make([]int, 0)
-	for _, _mtID := range strings.Split(string(b), ",") {
-		mtID, err := strconv.Atoi(strings.TrimSpace(_mtID))
+	parts := strings.Split(strings.TrimSpace(string(b)), ",")
+	if len(parts) == 1 {
+		// A single negative code rejects the whole request, such as invalid credentials.
+		mtID, err := strconv.Atoi(strings.TrimSpace(parts[0]))
 		if err != nil {
-			return nil, resp, owerr.New(owerr.UnknownError, "unknown error", resp.StatusCode)
+			return nil, resp, owerr.NewWithDetails(err, owerr.UnknownError, "unknown error", resp.StatusCode, details)
+		}
+		if mtID <= 0 {
+			return nil, resp, sendSMSError(mtID, resp.StatusCode, details)
 		}
-		mtIDs = append(mtIDs, mtID)
 	}
 
-	if len(mtIDs) <= 0 {
-		return nil, resp, owerr.New(owerr.UnknownError, "unknown error", resp.StatusCode)
+	results := make([]SendSMSResult, len(input.MobileNo))
+	for i, mobileNo := range input.MobileNo {
+		results[i].MobileNo = mobileNo
+		if i >= len(parts) {
+			results[i].Err = owerr.NewWithDetails(nil, owerr.UnknownError, "missing result for mobileno", resp.StatusCode, details)
+			continue
+		}
+		mtID, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		switch {
+		case err != nil:
+			results[i].Err = owerr.NewWithDetails(err, owerr.UnknownError, "unknown error", resp.StatusCode, details)
+		case mtID <= 0:
+			results[i].Err = sendSMSError(mtID, resp.StatusCode, details)
+		default:
+			results[i].MTID = mtID
+		}
 	}
+	return results, resp, nil
+}
 
-	if mtIDs[0] > 0 {
-		return &SendSMSOutput{MTIDs: mtIDs}, resp, nil
-	}
-	switch mtIDs[0] {
+// sendSMSError returns the error matching the negative code returned by the send SMS API.
+func sendSMSError(code, statusCode int, details owerr.Details) error {
+	details.GatewayCode = code
+	switch code {
 	case -100:
-		return nil, resp, owerr.New(owerr.InvalidCredentials, "apiusername or apipassword is invalid", resp.StatusCode)
+		return owerr.NewWithDetails(nil, owerr.InvalidCredentials, "apiusername or apipassword is invalid", statusCode, details)
 	case -200:
-		return nil, resp, owerr.New(owerr.InvalidSenderID, "senderid parameter is invalid", resp.StatusCode)
+		return owerr.NewWithDetails(nil, owerr.InvalidSenderID, "senderid parameter is invalid", statusCode, details)
 	case -300:
-		return nil, resp, owerr.New(owerr.InvalidMobileNo, "mobileno parameter is invalid", resp.StatusCode)
+		return owerr.NewWithDetails(nil, owerr.InvalidMobileNo, "mobileno parameter is invalid", statusCode, details)
 	case -400:
-		return nil, resp, owerr.New(owerr.InvalidLanguageType, "languagetype is invalid", resp.StatusCode)
+		return owerr.NewWithDetails(nil, owerr.InvalidLanguageType, "languagetype is invalid", statusCode, details)
 	case -500:
-		return nil, resp, owerr.New(owerr.InvalidMessageCharacters, "characters in message are invalid", resp.StatusCode)
+		return owerr.NewWithDetails(nil, owerr.InvalidMessageCharacters, "characters in message are invalid", statusCode, details)
 	case -600:
-		return nil, resp, owerr.New(owerr.InsufficientCreditBalance, "insufficient credit balance", resp.StatusCode)
+		return owerr.NewWithDetails(nil, owerr.InsufficientCreditBalance, "insufficient credit balance", statusCode, details)
 	default:
-		return nil, resp, owerr.New(owerr.UnknownError, "unknown error", resp.StatusCode)
+		return owerr.NewWithDetails(nil, owerr.UnknownError, "unknown error", statusCode, details)
 	}
 }
 
 // CheckTransactionStatus check transaction status based on mobile terminating ID provided.
+// The input is validated before any request is made, see CheckTransactionStatusInput.Validate.
 func (c *Client) CheckTransactionStatus(input *CheckTransactionStatusInput) (*CheckTransactionStatusOutput, *http.Response, error) {
-	requestURL := c.buildCheckTransactionStatusRequestURL(input)
+	return c.CheckTransactionStatusWithContext(context.Background(), input)
+}
 
-	resp, err := c.getRequest(requestURL)
+// CheckTransactionStatusWithContext same as CheckTransactionStatus, with the request bound to the context provided.
+func (c *Client) CheckTransactionStatusWithContext(ctx context.Context, input *CheckTransactionStatusInput) (*CheckTransactionStatusOutput, *http.Response, error) {
+	output, details, resp, err := c.lookupTransactionStatus(ctx, input)
 	if err != nil {
 		return nil, resp, err
 	}
+
+	details.GatewayCode = output.Code
+	switch output.Status {
+	case MTTransactionStatusNotFound:
+		return nil, resp, owerr.NewWithDetails(nil, owerr.MTInvalidNotFound, "mtid is invalid or not found", resp.StatusCode, details)
+	case MTTransactionStatusFailed:
+		return nil, resp, owerr.NewWithDetails(nil, owerr.MessageDeliveryFailure, "message delivery failed", resp.StatusCode, details)
+	case MTTransactionStatusUnknown:
+		return nil, resp, owerr.NewWithDetails(nil, owerr.UnknownError, "unknown error", resp.StatusCode, details)
+	default:
+		return output, resp, nil
+	}
+}
+
+// LookupTransactionStatus same as CheckTransactionStatus, except that every status returned by the gateway is reported
+// in the output instead of as an error, including MTTransactionStatusFailed, MTTransactionStatusNotFound and
+// MTTransactionStatusUnknown along with the raw code. An error is only returned when the lookup itself fails.
+func (c *Client) LookupTransactionStatus(input *CheckTransactionStatusInput) (*CheckTransactionStatusOutput, *http.Response, error) {
+	return c.LookupTransactionStatusWithContext(context.Background(), input)
+}
+
+// LookupTransactionStatusWithContext same as LookupTransactionStatus, with the request bound to the context provided.
+func (c *Client) LookupTransactionStatusWithContext(ctx context.Context, input *CheckTransactionStatusInput) (*CheckTransactionStatusOutput, *http.Response, error) {
+	output, _, resp, err := c.lookupTransactionStatus(ctx, input)
+	return output, resp, err
+}
+
+// lookupTransactionStatus looks up the transaction status, also returning the details of the response for errors.
+func (c *Client) lookupTransactionStatus(ctx context.Context, input *CheckTransactionStatusInput) (*CheckTransactionStatusOutput, owerr.Details, *http.Response, error) {
+	if err := input.Validate(); err != nil {
+		return nil, owerr.Details{}, nil, err
+	}
+
+	requestURL := c.buildCheckTransactionStatusRequestURL(input)
+	details := owerr.Details{Path: requestPath(requestURL), MTID: input.MTID}
+
+	resp, err := c.doRequest(ctx, requestURL, true, c.queryLimiter)
+	if err != nil {
+		return nil, details, resp, err
+	}
 	defer resp.Body.Close()
 
 	b, err := ioutil.ReadAll(resp.Body)
+	details.Body = string(b)
 	if err != nil {
-		return nil, resp, err
+		return nil, details, resp, owerr.NewWithDetails(err, owerr.RequestFailure, "failed to read response", resp.StatusCode, details)
 	}
 
-	status, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	code, err := strconv.Atoi(strings.TrimSpace(string(b)))
 	if err != nil {
-		return nil, resp, owerr.New(owerr.UnknownError, "unknown error", resp.StatusCode)
+		return nil, details, resp, owerr.NewWithDetails(err, owerr.UnknownError, "unknown error", resp.StatusCode, details)
 	}
 
-	switch status {
-	case 0:
-		return &CheckTransactionStatusOutput{Status: MTTransactionStatusSuccess}, resp, nil
-	case 100:
-		return &CheckTransactionStatusOutput{Status: MTTransactionStatusTelcoDelivered}, resp, nil
-	case -100:
-		return nil, resp, owerr.New(owerr.MTInvalidNotFound, "mtid is invalid or not found", resp.StatusCode)
-	case -200:
-		return nil, resp, owerr.New(owerr.MessageDeliveryFailure, "message delivery failed", resp.StatusCode)
-	default:
-		return nil, resp, owerr.New(owerr.UnknownError, "unknown error", resp.StatusCode)
+	return &CheckTransactionStatusOutput{Status: ParseTransactionStatus(code), Code: code}, details, resp, nil
+}
+
+// CheckTransactionStatuses looks up the transaction status of many mobile terminating IDs concurrently, duplicates
+// being looked up once, bounded by WithLookupConcurrency. Each MTID maps to its result, which holds either the status
+// reported by LookupTransactionStatus or the error of its lookup, so that one bad MTID does not fail the others.
+func (c *Client) CheckTransactionStatuses(ctx context.Context, mtIDs []int) map[int]TransactionStatusResult {
+	concurrency := c.lookupConcurrency
+	if concurrency < 1 {
+		concurrency = defaultLookupConcurrency
+	}
+
+	var (
+		results = make(map[int]TransactionStatusResult, len(mtIDs))
+		seen    = make(map[int]bool, len(mtIDs))
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, concurrency)
+	)
+	for _, mtID := range mtIDs {
+		if seen[mtID] {
+			continue
+		}
+		seen[mtID] = true
+
+		wg.Add(1)
+		go func(mtID int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := TransactionStatusResult{MTID: mtID}
+			output, _, err := c.LookupTransactionStatusWithContext(ctx, &CheckTransactionStatusInput{MTID: mtID})
+			if err != nil {
+				result.Err = err
+			} else {
+				result.Status, result.Code = output.Status, output.Code
+			}
+
+			mu.Lock()
+			results[mtID] = result
+			mu.Unlock()
+		}(mtID)
 	}
+	wg.Wait()
+	return results
 }
 
 // CheckCreditBalance check remaining credit balance based on API Username and Password from client's config.
 func (c *Client) CheckCreditBalance() (*CheckCreditBalanceOutput, *http.Response, error) {
+	return c.CheckCreditBalanceWithContext(context.Background())
+}
+
+// CheckCreditBalanceWithContext same as CheckCreditBalance, with the request bound to the context provided.
+func (c *Client) CheckCreditBalanceWithContext(ctx context.Context) (*CheckCreditBalanceOutput, *http.Response, error) {
 	requestURL := c.buildCheckCreditBalanceRequestURL()
 
-	resp, err := c.getRequest(requestURL)
+	resp, err := c.doRequest(ctx, requestURL, true, c.queryLimiter)
 	if err != nil {
 		return nil, resp, err
 	}
 	defer resp.Body.Close()
 
 	b, err := ioutil.ReadAll(resp.Body)
+	details := owerr.Details{Path: requestPath(requestURL), Body: string(b)}
 	if err != nil {
-		return nil, resp, err
+		return nil, resp, owerr.NewWithDetails(err, owerr.RequestFailure, "failed to read response", resp.StatusCode, details)
 	}
 
 	creditBalance, err := strconv.ParseFloat(strings.TrimSpace(string(b)), 32)
 	if err != nil {
-		return nil, resp, owerr.New(owerr.UnknownError, "unknown error", resp.StatusCode)
+		return nil, resp, owerr.NewWithDetails(err, owerr.UnknownError, "unknown error", resp.StatusCode, details)
 	}
 
 	if creditBalance >= 0 {
 		return &CheckCreditBalanceOutput{CreditBalance: float32(creditBalance)}, resp, nil
 	}
 
+	details.GatewayCode = int(creditBalance)
 	switch creditBalance {
 	case -100:
-		return nil, resp, owerr.New(owerr.InvalidCredentials, "apiusername or apipassword is invalid", resp.StatusCode)
+		return nil, resp, owerr.NewWithDetails(nil, owerr.InvalidCredentials, "apiusername or apipassword is invalid", resp.StatusCode, details)
 	default:
-		return nil, resp, owerr.New(owerr.UnknownError, "unknown error", resp.StatusCode)
+		return nil, resp, owerr.NewWithDetails(nil, owerr.UnknownError, "unknown error", resp.StatusCode, details)
 	}
 }
@@ -5,10 +5,17 @@
 package owsms_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/junwen-k/onewaysms-sdk-go/owerr"
 	"github.com/junwen-k/onewaysms-sdk-go/owsms"
@@ -56,6 +63,119 @@ func TestSendSMS(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, output)
 		assert.Equal(t, []int{145712468, 145712469}, output.MTIDs)
+		assert.Equal(t, []owsms.SendSMSResult{
+			{MobileNo: "60123456789", MTID: 145712468},
+			{MobileNo: "60129876543", MTID: 145712469},
+		}, output.Results)
+	})
+
+	t.Run("With partially rejected mobileNo", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintln(w, "145712468,-300,random")
+		}))
+		defer ts.Close()
+
+		svc = owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		output, _, err = svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789", "invalid", "60129876543", "60121111111"},
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, output)
+		assert.Equal(t, []int{145712468}, output.MTIDs)
+		assert.Len(t, output.Results, 4)
+		assert.Equal(t, owsms.SendSMSResult{MobileNo: "60123456789", MTID: 145712468}, output.Results[0])
+
+		for i, expected := range []struct {
+			mobileNo string
+			code     string
+			message  string
+		}{
+			{"invalid", owerr.InvalidMobileNo, "mobileno parameter is invalid"},
+			{"60129876543", owerr.UnknownError, "unknown error"},
+			{"60121111111", owerr.UnknownError, "missing result for mobileno"},
+		} {
+			result := output.Results[i+1]
+			assert.Equal(t, expected.mobileNo, result.MobileNo)
+			assert.Zero(t, result.MTID)
+			owErr, ok := result.Err.(owerr.Error)
+			assert.True(t, ok)
+			assert.Equal(t, expected.code, owErr.Code())
+			assert.Equal(t, expected.message, owErr.Message())
+		}
+	})
+
+	t.Run("With every mobileNo rejected", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintln(w, "-600,-600")
+		}))
+		defer ts.Close()
+
+		svc = owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		output, _, err = svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789", "60129876543"},
+		})
+		owErr, ok := err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.InsufficientCreditBalance, owErr.Code())
+		assert.NotNil(t, output)
+		assert.Empty(t, output.MTIDs)
+		assert.Len(t, output.Results, 2)
+		assert.Equal(t, err, output.Results[0].Err)
+		assert.Error(t, output.Results[1].Err)
+	})
+
+	t.Run("With emoji message", func(t *testing.T) {
+		var query url.Values
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			fmt.Fprintln(w, "145712468")
+		}))
+		defer ts.Close()
+
+		svc = owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		output, _, err = svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hi 😀",
+			MobileNo: []string{"60123456789"},
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, output)
+		assert.Equal(t, string(owsms.LanguageTypeUnicode), query.Get("languagetype"))
+		assert.Equal(t, "004800690020D83DDE00", query.Get("message"))
+	})
+
+	t.Run("With input sent twice", func(t *testing.T) {
+		var queries []url.Values
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			queries = append(queries, r.URL.Query())
+			fmt.Fprintln(w, "145712468")
+		}))
+		defer ts.Close()
+
+		svc = owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		input := &owsms.SendSMSInput{
+			Message:  "Hello, 世界",
+			MobileNo: []string{"60123456789"},
+		}
+		for i := 0; i < 2; i++ {
+			_, _, err = svc.SendSMS(input)
+			assert.NoError(t, err)
+		}
+		assert.Len(t, queries, 2)
+		assert.Equal(t, "00480065006C006C006F002C00204E16754C", queries[0].Get("message"))
+		assert.Equal(t, queries[0].Get("message"), queries[1].Get("message"))
+		assert.Equal(t, queries[0].Get("languagetype"), queries[1].Get("languagetype"))
+		assert.Equal(t, &owsms.SendSMSInput{
+			Message:  "Hello, 世界",
+			MobileNo: []string{"60123456789"},
+		}, input)
 	})
 
 	t.Run("With request failure", func(t *testing.T) {
@@ -71,13 +191,36 @@ func TestSendSMS(t *testing.T) {
 			MobileNo: []string{"60123456789"},
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 500 (Internal Server Error): request failure")
+		assert.EqualError(t, err, "OneWaySMS: Error 500 (Internal Server Error): request failure (path /api.aspx)")
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "request failure", owErr.Message())
 		assert.Equal(t, owerr.RequestFailure, owErr.Code())
 		assert.Equal(t, http.StatusInternalServerError, owErr.StatusCode())
-		assert.Nil(t, output)
+		assert.Equal(t, err, output.Results[0].Err)
+	})
+
+	t.Run("With request failure and rejected mobileNo", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithPhoneNormalization("MY"))
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"123", "60123456789"},
+		})
+		owErr, ok := err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.RequestFailure, owErr.Code())
+		assert.Empty(t, output.MTIDs)
+		assert.Len(t, output.Results, 2)
+		owErr, ok = output.Results[0].Err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.InvalidParameter, owErr.Code())
+		assert.Equal(t, owsms.SendSMSResult{MobileNo: "60123456789", Err: err}, output.Results[1])
 	})
 
 	t.Run("With invalid user credentials", func(t *testing.T) {
@@ -93,13 +236,13 @@ func TestSendSMS(t *testing.T) {
 			MobileNo: []string{"60123456789"},
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): apiusername or apipassword is invalid")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): apiusername or apipassword is invalid (path /api.aspx, gateway code -100, body "-100")`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "apiusername or apipassword is invalid", owErr.Message())
 		assert.Equal(t, owerr.InvalidCredentials, owErr.Code())
 		assert.Equal(t, http.StatusOK, owErr.StatusCode())
-		assert.Nil(t, output)
+		assert.Equal(t, err, output.Results[0].Err)
 	})
 
 	t.Run("With invalid senderID", func(t *testing.T) {
@@ -115,13 +258,13 @@ func TestSendSMS(t *testing.T) {
 			MobileNo: []string{"60123456789"},
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): senderid parameter is invalid")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): senderid parameter is invalid (path /api.aspx, gateway code -200, body "-200")`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "senderid parameter is invalid", owErr.Message())
 		assert.Equal(t, owerr.InvalidSenderID, owErr.Code())
 		assert.Equal(t, http.StatusOK, owErr.StatusCode())
-		assert.Nil(t, output)
+		assert.Equal(t, err, output.Results[0].Err)
 	})
 
 	t.Run("With invalid mobileNo", func(t *testing.T) {
@@ -137,13 +280,13 @@ func TestSendSMS(t *testing.T) {
 			MobileNo: []string{"invalid"},
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): mobileno parameter is invalid")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): mobileno parameter is invalid (path /api.aspx, gateway code -300, body "-300")`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "mobileno parameter is invalid", owErr.Message())
 		assert.Equal(t, owerr.InvalidMobileNo, owErr.Code())
 		assert.Equal(t, http.StatusOK, owErr.StatusCode())
-		assert.Nil(t, output)
+		assert.Equal(t, err, output.Results[0].Err)
 	})
 
 	t.Run("With invalid languageType", func(t *testing.T) {
@@ -157,15 +300,39 @@ func TestSendSMS(t *testing.T) {
 		output, _, err = svc.SendSMS(&owsms.SendSMSInput{
 			Message:      "Hello World",
 			MobileNo:     []string{"60123456789"},
-			LanguageType: "invalid",
+			LanguageType: owsms.LanguageTypeNormal,
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): languagetype is invalid")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): languagetype is invalid (path /api.aspx, gateway code -400, body "-400")`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "languagetype is invalid", owErr.Message())
 		assert.Equal(t, owerr.InvalidLanguageType, owErr.Code())
 		assert.Equal(t, http.StatusOK, owErr.StatusCode())
+		assert.Equal(t, err, output.Results[0].Err)
+	})
+
+	t.Run("With invalid input", func(t *testing.T) {
+		var called bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer ts.Close()
+
+		svc = owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		output, _, err = svc.SendSMS(&owsms.SendSMSInput{
+			Message:      "Hello World",
+			MobileNo:     []string{"60123456789"},
+			LanguageType: "invalid",
+		})
+		assert.Error(t, err)
+		assert.EqualError(t, err, "SendSMSInput: Error: LanguageType is invalid")
+		vErr, ok := err.(owerr.ValidationError)
+		assert.True(t, ok)
+		assert.Equal(t, "LanguageType", vErr.Field())
+		assert.Equal(t, owerr.InvalidParameter, vErr.Code())
+		assert.False(t, called)
 		assert.Nil(t, output)
 	})
 
@@ -182,13 +349,13 @@ func TestSendSMS(t *testing.T) {
 			MobileNo: []string{"60123456789"},
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): characters in message are invalid")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): characters in message are invalid (path /api.aspx, gateway code -500, body "-500")`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "characters in message are invalid", owErr.Message())
 		assert.Equal(t, owerr.InvalidMessageCharacters, owErr.Code())
 		assert.Equal(t, http.StatusOK, owErr.StatusCode())
-		assert.Nil(t, output)
+		assert.Equal(t, err, output.Results[0].Err)
 	})
 
 	t.Run("With insufficient credit balance", func(t *testing.T) {
@@ -204,13 +371,13 @@ func TestSendSMS(t *testing.T) {
 			MobileNo: []string{"60123456789"},
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): insufficient credit balance")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): insufficient credit balance (path /api.aspx, gateway code -600, body "-600")`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "insufficient credit balance", owErr.Message())
 		assert.Equal(t, owerr.InsufficientCreditBalance, owErr.Code())
 		assert.Equal(t, http.StatusOK, owErr.StatusCode())
-		assert.Nil(t, output)
+		assert.Equal(t, err, output.Results[0].Err)
 	})
 
 	t.Run("With unknown error", func(t *testing.T) {
@@ -226,13 +393,15 @@ func TestSendSMS(t *testing.T) {
 			MobileNo: []string{"60123456789"},
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): unknown error")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): unknown error (path /api.aspx, body "random"): strconv.Atoi: parsing "random": invalid syntax`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "unknown error", owErr.Message())
 		assert.Equal(t, owerr.UnknownError, owErr.Code())
+		var numErr *strconv.NumError
+		assert.True(t, errors.As(err, &numErr))
 		assert.Equal(t, http.StatusOK, owErr.StatusCode())
-		assert.Nil(t, output)
+		assert.Equal(t, err, output.Results[0].Err)
 	})
 }
 
@@ -287,7 +456,7 @@ func TestCheckTransactionStatus(t *testing.T) {
 			MTID: 1,
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): mtid is invalid or not found")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): mtid is invalid or not found (path /bulktrx.aspx, gateway code -100, mtid 1, body "-100")`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "mtid is invalid or not found", owErr.Message())
@@ -296,6 +465,26 @@ func TestCheckTransactionStatus(t *testing.T) {
 		assert.Nil(t, output)
 	})
 
+	t.Run("With missing mtID", func(t *testing.T) {
+		var called bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer ts.Close()
+
+		svc = owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		output, _, err = svc.CheckTransactionStatus(&owsms.CheckTransactionStatusInput{})
+		assert.Error(t, err)
+		assert.EqualError(t, err, "CheckTransactionStatusInput: Error: MTID is required")
+		vErr, ok := err.(owerr.ValidationError)
+		assert.True(t, ok)
+		assert.Equal(t, "MTID", vErr.Field())
+		assert.Equal(t, owerr.InvalidParameter, vErr.Code())
+		assert.False(t, called)
+		assert.Nil(t, output)
+	})
+
 	t.Run("With failed mtID", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprintln(w, "-200")
@@ -308,7 +497,7 @@ func TestCheckTransactionStatus(t *testing.T) {
 			MTID: 1,
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): message delivery failed")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): message delivery failed (path /bulktrx.aspx, gateway code -200, mtid 1, body "-200")`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "message delivery failed", owErr.Message())
@@ -329,16 +518,81 @@ func TestCheckTransactionStatus(t *testing.T) {
 			MTID: 1,
 		})
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): unknown error")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): unknown error (path /bulktrx.aspx, mtid 1, body "random"): strconv.Atoi: parsing "random": invalid syntax`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "unknown error", owErr.Message())
 		assert.Equal(t, owerr.UnknownError, owErr.Code())
+		var numErr *strconv.NumError
+		assert.True(t, errors.As(err, &numErr))
 		assert.Equal(t, http.StatusOK, owErr.StatusCode())
 		assert.Nil(t, output)
 	})
 }
 
+func TestLookupTransactionStatus(t *testing.T) {
+	tests := []struct {
+		desc     string
+		response string
+		expected *owsms.CheckTransactionStatusOutput
+	}{
+		{
+			desc:     "With success status",
+			response: "0",
+			expected: &owsms.CheckTransactionStatusOutput{Status: owsms.MTTransactionStatusSuccess, Code: 0},
+		},
+		{
+			desc:     "With telco_delivered status",
+			response: "100",
+			expected: &owsms.CheckTransactionStatusOutput{Status: owsms.MTTransactionStatusTelcoDelivered, Code: 100},
+		},
+		{
+			desc:     "With not_found status",
+			response: "-100",
+			expected: &owsms.CheckTransactionStatusOutput{Status: owsms.MTTransactionStatusNotFound, Code: -100},
+		},
+		{
+			desc:     "With failed status",
+			response: "-200",
+			expected: &owsms.CheckTransactionStatusOutput{Status: owsms.MTTransactionStatusFailed, Code: -200},
+		},
+		{
+			desc:     "With unknown status",
+			response: "-999",
+			expected: &owsms.CheckTransactionStatusOutput{Status: owsms.MTTransactionStatusUnknown, Code: -999},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, test.response)
+			}))
+			defer ts.Close()
+
+			svc := owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+			output, _, err := svc.LookupTransactionStatus(&owsms.CheckTransactionStatusInput{MTID: 145712470})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, output)
+		})
+	}
+
+	t.Run("With unknown error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, "random")
+		}))
+		defer ts.Close()
+
+		svc := owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		output, _, err := svc.LookupTransactionStatus(&owsms.CheckTransactionStatusInput{MTID: 145712470})
+		owErr, ok := err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.UnknownError, owErr.Code())
+		assert.Nil(t, output)
+	})
+}
+
 func TestCheckCreditBalance(t *testing.T) {
 	var (
 		svc    *owsms.Client
@@ -370,7 +624,7 @@ func TestCheckCreditBalance(t *testing.T) {
 
 		output, _, err = svc.CheckCreditBalance()
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): apiusername or apipassword is invalid")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): apiusername or apipassword is invalid (path /bulkcredit.aspx, gateway code -100, body "-100")`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "apiusername or apipassword is invalid", owErr.Message())
@@ -389,12 +643,379 @@ func TestCheckCreditBalance(t *testing.T) {
 
 		output, _, err = svc.CheckCreditBalance()
 		assert.Error(t, err)
-		assert.EqualError(t, err, "OneWaySMS: Error 200 (OK): unknown error")
+		assert.EqualError(t, err, `OneWaySMS: Error 200 (OK): unknown error (path /bulkcredit.aspx, body "random"): strconv.ParseFloat: parsing "random": invalid syntax`)
 		owErr, ok := err.(owerr.Error)
 		assert.True(t, ok)
 		assert.Equal(t, "unknown error", owErr.Message())
 		assert.Equal(t, owerr.UnknownError, owErr.Code())
+		var numErr *strconv.NumError
+		assert.True(t, errors.As(err, &numErr))
 		assert.Equal(t, http.StatusOK, owErr.StatusCode())
 		assert.Nil(t, output)
 	})
 }
+
+func TestClientWithContext(t *testing.T) {
+	newBlockingServer := func() *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+	}
+
+	t.Run("SendSMSWithContext with cancelled context", func(t *testing.T) {
+		ts := newBlockingServer()
+		defer ts.Close()
+
+		svc := owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		output, _, err := svc.SendSMSWithContext(ctx, &owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789"},
+		})
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, err, output.Results[0].Err)
+	})
+
+	t.Run("CheckTransactionStatusWithContext with cancelled context", func(t *testing.T) {
+		ts := newBlockingServer()
+		defer ts.Close()
+
+		svc := owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		output, _, err := svc.CheckTransactionStatusWithContext(ctx, &owsms.CheckTransactionStatusInput{
+			MTID: 145712470,
+		})
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Nil(t, output)
+	})
+
+	t.Run("CheckCreditBalanceWithContext with exceeded deadline", func(t *testing.T) {
+		ts := newBlockingServer()
+		defer ts.Close()
+
+		svc := owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		output, _, err := svc.CheckCreditBalanceWithContext(ctx)
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Nil(t, output)
+	})
+
+	t.Run("With exceeded deadline and credentials", func(t *testing.T) {
+		ts := newBlockingServer()
+		defer ts.Close()
+
+		svc := owsms.NewClient(ts.URL, "Username", "S3CRET", "SenderID")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, _, err := svc.SendSMSWithContext(ctx, &owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789"},
+		})
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.NotContains(t, err.Error(), "S3CRET")
+		assert.NotContains(t, err.Error(), "Username")
+
+		_, _, err = svc.CheckCreditBalanceWithContext(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.NotContains(t, err.Error(), "S3CRET")
+	})
+
+	t.Run("With already cancelled context", func(t *testing.T) {
+		var called bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			fmt.Fprintln(w, "6500.5")
+		}))
+		defer ts.Close()
+
+		svc := owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		output, _, err := svc.CheckCreditBalanceWithContext(ctx)
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.False(t, called)
+		assert.Nil(t, output)
+	})
+}
+
+func TestSendSMSBatches(t *testing.T) {
+	const maxURLLength = 1024
+
+	mobileNo := make([]string, 250)
+	for i := range mobileNo {
+		mobileNo[i] = fmt.Sprintf("6012%07d", i)
+	}
+
+	// newBatchServer returns a server responding with an MTID derived from each number,
+	// rejecting URLs longer than maxURLLength and responding with failure to batches containing it.
+	newBatchServer := func(failure string, calls, inFlight, maxInFlight *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(calls, 1)
+			n := atomic.AddInt32(inFlight, 1)
+			defer atomic.AddInt32(inFlight, -1)
+			for {
+				max := atomic.LoadInt32(maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			if len(r.URL.String()) > maxURLLength {
+				w.WriteHeader(http.StatusRequestURITooLong)
+				return
+			}
+			numbers := strings.Split(r.URL.Query().Get("mobileno"), ",")
+			mtIDs := make([]string, len(numbers))
+			for i, number := range numbers {
+				if number == failure || failure == "*" {
+					fmt.Fprintln(w, "-600")
+					return
+				}
+				id, _ := strconv.Atoi(number[4:])
+				mtIDs[i] = strconv.Itoa(100000 + id)
+			}
+			fmt.Fprintln(w, strings.Join(mtIDs, ","))
+		}))
+	}
+
+	t.Run("With batches", func(t *testing.T) {
+		var calls, inFlight, maxInFlight int32
+		ts := newBatchServer("", &calls, &inFlight, &maxInFlight)
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithBatchSize(20), owsms.WithBatchConcurrency(4))
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: mobileNo,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, output.MTIDs, 250)
+		assert.Len(t, output.Results, 250)
+		for i, result := range output.Results {
+			assert.Equal(t, mobileNo[i], result.MobileNo)
+			assert.Equal(t, 100000+i, result.MTID)
+			assert.Equal(t, 100000+i, output.MTIDs[i])
+			assert.NoError(t, result.Err)
+		}
+		assert.Equal(t, int32(13), atomic.LoadInt32(&calls))
+		assert.True(t, atomic.LoadInt32(&maxInFlight) <= 4)
+	})
+
+	t.Run("Without batches", func(t *testing.T) {
+		var calls, inFlight, maxInFlight int32
+		ts := newBatchServer("", &calls, &inFlight, &maxInFlight)
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL))
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: mobileNo,
+		})
+		assert.Error(t, err)
+		owErr, ok := err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.RequestFailure, owErr.Code())
+		assert.Equal(t, http.StatusRequestURITooLong, owErr.StatusCode())
+		assert.Equal(t, err, output.Results[0].Err)
+	})
+
+	t.Run("With failed batch", func(t *testing.T) {
+		var calls, inFlight, maxInFlight int32
+		ts := newBatchServer(mobileNo[45], &calls, &inFlight, &maxInFlight)
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithBatchSize(20), owsms.WithBatchConcurrency(4))
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: mobileNo,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, output.MTIDs, 230)
+		for i, result := range output.Results {
+			assert.Equal(t, mobileNo[i], result.MobileNo)
+			if i >= 40 && i < 60 {
+				owErr, ok := result.Err.(owerr.Error)
+				assert.True(t, ok)
+				assert.Equal(t, owerr.InsufficientCreditBalance, owErr.Code())
+				assert.Zero(t, result.MTID)
+				continue
+			}
+			assert.NoError(t, result.Err)
+			assert.Equal(t, 100000+i, result.MTID)
+		}
+	})
+
+	t.Run("With every batch failed", func(t *testing.T) {
+		var calls, inFlight, maxInFlight int32
+		ts := newBatchServer("*", &calls, &inFlight, &maxInFlight)
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithBatchSize(50))
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: mobileNo,
+		})
+		assert.Error(t, err)
+		owErr, ok := err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.InsufficientCreditBalance, owErr.Code())
+		assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&maxInFlight))
+		assert.Empty(t, output.MTIDs)
+		assert.Len(t, output.Results, len(mobileNo))
+		assert.Equal(t, err, output.Results[len(mobileNo)-1].Err)
+	})
+}
+
+func TestCheckTransactionStatuses(t *testing.T) {
+	var calls, inFlight, maxInFlight int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		switch r.URL.Query().Get("mtid") {
+		case "2":
+			fmt.Fprintln(w, "100")
+		case "3":
+			fmt.Fprintln(w, "-200")
+		case "4":
+			fmt.Fprintln(w, "-100")
+		case "5":
+			fmt.Fprintln(w, "random")
+		default:
+			fmt.Fprintln(w, "0")
+		}
+	}))
+	defer ts.Close()
+
+	svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithLookupConcurrency(2))
+
+	results := svc.CheckTransactionStatuses(context.Background(), []int{1, 2, 3, 4, 5, 0, 1, 2, 6, 7})
+	assert.Len(t, results, 8)
+	assert.Equal(t, int32(7), atomic.LoadInt32(&calls))
+	assert.True(t, atomic.LoadInt32(&maxInFlight) <= 2)
+
+	assert.Equal(t, owsms.TransactionStatusResult{MTID: 1, Status: owsms.MTTransactionStatusSuccess, Code: 0}, results[1])
+	assert.Equal(t, owsms.TransactionStatusResult{MTID: 2, Status: owsms.MTTransactionStatusTelcoDelivered, Code: 100}, results[2])
+	assert.Equal(t, owsms.TransactionStatusResult{MTID: 3, Status: owsms.MTTransactionStatusFailed, Code: -200}, results[3])
+	assert.Equal(t, owsms.TransactionStatusResult{MTID: 4, Status: owsms.MTTransactionStatusNotFound, Code: -100}, results[4])
+	assert.Equal(t, owsms.TransactionStatusResult{MTID: 6, Status: owsms.MTTransactionStatusSuccess, Code: 0}, results[6])
+	assert.Equal(t, owsms.TransactionStatusResult{MTID: 7, Status: owsms.MTTransactionStatusSuccess, Code: 0}, results[7])
+
+	for mtID, code := range map[int]string{5: owerr.UnknownError, 0: owerr.InvalidParameter} {
+		result := results[mtID]
+		assert.Equal(t, mtID, result.MTID)
+		assert.Empty(t, result.Status)
+		owErr, ok := result.Err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, code, owErr.Code())
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	t.Run("With network error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		ts.Close()
+
+		svc := owsms.NewClient(ts.URL, "Username", "Secret123", "SenderID")
+
+		output, _, err := svc.CheckCreditBalance()
+		assert.True(t, errors.Is(err, owerr.ErrRequestFailure))
+		var apiErr *owerr.APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, owerr.RequestFailure, apiErr.Code())
+		assert.Equal(t, owerr.Details{Path: "/bulkcredit.aspx"}, apiErr.Details())
+		var urlErr *url.Error
+		assert.True(t, errors.As(err, &urlErr))
+		assert.NotContains(t, urlErr.Error(), "Secret123")
+		assert.NotContains(t, err.Error(), "Secret123")
+		assert.NotContains(t, err.Error(), "Username")
+		assert.Contains(t, err.Error(), "apipassword=REDACTED")
+		assert.True(t, strings.HasPrefix(err.Error(), "OneWaySMS: Error: request failure (path /bulkcredit.aspx): "))
+		assert.Nil(t, output)
+	})
+
+	t.Run("With gateway error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, "-600")
+		}))
+		defer ts.Close()
+
+		svc := owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		_, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789"},
+		})
+		assert.True(t, errors.Is(err, owerr.ErrInsufficientCreditBalance))
+		assert.False(t, errors.Is(err, owerr.ErrRequestFailure))
+
+		var apiErr *owerr.APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, owerr.Details{Path: "/api.aspx", Body: "-600\n", GatewayCode: -600}, apiErr.Details())
+	})
+
+	t.Run("With per-recipient gateway error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "145712468,-300")
+		}))
+		defer ts.Close()
+
+		svc := owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789", "6012"},
+		})
+		assert.NoError(t, err)
+		assert.EqualError(t, output.Results[1].Err, `OneWaySMS: Error 200 (OK): mobileno parameter is invalid (path /api.aspx, gateway code -300, body "145712468,-300")`)
+	})
+
+	t.Run("With long response body", func(t *testing.T) {
+		body := strings.Repeat("<html>", 100)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		}))
+		defer ts.Close()
+
+		svc := owsms.NewClient(ts.URL, "Username", "Password", "SenderID")
+
+		_, _, err := svc.CheckTransactionStatus(&owsms.CheckTransactionStatusInput{MTID: 145712470})
+		var apiErr *owerr.APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, owerr.UnknownError, apiErr.Code())
+		assert.Equal(t, body[:owerr.MaxBodyLength]+"...", apiErr.Details().Body)
+		assert.Equal(t, 145712470, apiErr.Details().MTID)
+		assert.Equal(t, "/bulktrx.aspx", apiErr.Details().Path)
+	})
+}
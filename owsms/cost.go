@@ -0,0 +1,86 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+const (
+	normalSingleLength    = 160 // GSM septets fitting in a single MT.
+	normalSegmentLength   = 153 // GSM septets fitting in each MT of a concatenated message.
+	unicodeSingleLength   = 70  // UTF-16 code units fitting in a single MT.
+	unicodeSegmentLength  = 67  // UTF-16 code units fitting in each MT of a concatenated message.
+	normalExtensionLength = 2   // GSM septets taken by an extension table character.
+)
+
+// CostEstimate send SMS cost estimate structure.
+type CostEstimate struct {
+	LanguageType LanguageType // Language Type the message is sent with, either defined in the input or detected.
+	Characters   int          // Length of the message, in GSM septets for LanguageTypeNormal or UTF-16 code units for LanguageTypeUnicode.
+	Segments     int          // Number of MT the message is split into, for each recipient.
+	Credits      int          // Total number of MT across all recipients, consumed from the credit balance.
+}
+
+// EstimateCost estimates the number of MT and credits the send SMS input would consume, without calling the gateway.
+// Concatenated messages carry a header reducing each MT to 153 GSM septets or 67 UTF-16 code units,
+// and GSM extension table characters such as € count as 2 characters.
+// Escape sequences and surrogate pairs are never split across MT.
+func EstimateCost(input *SendSMSInput) *CostEstimate {
+	languageType := input.LanguageType
+	if languageType == "" {
+		languageType = DetectLanguageType(input.Message)
+	}
+
+	var (
+		units                 []int
+		singleLen, segmentLen int
+	)
+	if languageType == LanguageTypeUnicode {
+		singleLen, segmentLen = unicodeSingleLength, unicodeSegmentLength
+		for _, r := range input.Message {
+			if r > 0xFFFF {
+				// Encoded as a surrogate pair.
+				units = append(units, 2)
+			} else {
+				units = append(units, 1)
+			}
+		}
+	} else {
+		singleLen, segmentLen = normalSingleLength, normalSegmentLength
+		for _, r := range input.Message {
+			if IsGSM7Extension(r) {
+				units = append(units, normalExtensionLength)
+			} else {
+				units = append(units, 1)
+			}
+		}
+	}
+
+	characters := 0
+	for _, u := range units {
+		characters += u
+	}
+
+	segments := 0
+	switch {
+	case characters == 0:
+	case characters <= singleLen:
+		segments = 1
+	default:
+		segments = 1
+		used := 0
+		for _, u := range units {
+			if used+u > segmentLen {
+				segments++
+				used = 0
+			}
+			used += u
+		}
+	}
+
+	return &CostEstimate{
+		LanguageType: languageType,
+		Characters:   characters,
+		Segments:     segments,
+		Credits:      segments * len(input.MobileNo),
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		desc     string
+		input    *owsms.SendSMSInput
+		expected *owsms.CostEstimate
+	}{
+		{
+			desc: "With empty message",
+			input: &owsms.SendSMSInput{
+				MobileNo: []string{"60123456789"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeNormal},
+		},
+		{
+			desc: "With short normal message",
+			input: &owsms.SendSMSInput{
+				Message:  "Hello World",
+				MobileNo: []string{"60123456789"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeNormal, Characters: 11, Segments: 1, Credits: 1},
+		},
+		{
+			desc: "With 160 characters normal message",
+			input: &owsms.SendSMSInput{
+				Message:  strings.Repeat("a", 160),
+				MobileNo: []string{"60123456789", "60129876543"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeNormal, Characters: 160, Segments: 1, Credits: 2},
+		},
+		{
+			desc: "With 161 characters normal message",
+			input: &owsms.SendSMSInput{
+				Message:  strings.Repeat("a", 161),
+				MobileNo: []string{"60123456789", "60129876543"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeNormal, Characters: 161, Segments: 2, Credits: 4},
+		},
+		{
+			desc: "With 306 characters normal message",
+			input: &owsms.SendSMSInput{
+				Message:  strings.Repeat("a", 306),
+				MobileNo: []string{"60123456789"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeNormal, Characters: 306, Segments: 2, Credits: 2},
+		},
+		{
+			desc: "With extension characters",
+			input: &owsms.SendSMSInput{
+				Message:  strings.Repeat("€", 80),
+				MobileNo: []string{"60123456789"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeNormal, Characters: 160, Segments: 1, Credits: 1},
+		},
+		{
+			desc: "With extension character across segment boundary",
+			input: &owsms.SendSMSInput{
+				Message:  strings.Repeat("a", 152) + "€" + strings.Repeat("a", 152),
+				MobileNo: []string{"60123456789"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeNormal, Characters: 306, Segments: 3, Credits: 3},
+		},
+		{
+			desc: "With 70 characters unicode message",
+			input: &owsms.SendSMSInput{
+				Message:  strings.Repeat("世", 70),
+				MobileNo: []string{"60123456789"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeUnicode, Characters: 70, Segments: 1, Credits: 1},
+		},
+		{
+			desc: "With 71 characters unicode message",
+			input: &owsms.SendSMSInput{
+				Message:  strings.Repeat("世", 71),
+				MobileNo: []string{"60123456789"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeUnicode, Characters: 71, Segments: 2, Credits: 2},
+		},
+		{
+			desc: "With emoji across segment boundary",
+			input: &owsms.SendSMSInput{
+				Message:  strings.Repeat("世", 66) + "😀" + strings.Repeat("世", 66),
+				MobileNo: []string{"60123456789"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeUnicode, Characters: 134, Segments: 3, Credits: 3},
+		},
+		{
+			desc: "With explicit unicode language type",
+			input: &owsms.SendSMSInput{
+				LanguageType: owsms.LanguageTypeUnicode,
+				Message:      "Hello World",
+				MobileNo:     []string{"60123456789"},
+			},
+			expected: &owsms.CostEstimate{LanguageType: owsms.LanguageTypeUnicode, Characters: 11, Segments: 1, Credits: 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, owsms.EstimateCost(test.input))
+		})
+	}
+}
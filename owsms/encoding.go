@@ -0,0 +1,50 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+const hexDigits = "0123456789ABCDEF"
+
+// EncodeUnicodeMessage encodes the message as uppercase UTF-16BE hexadecimal, 4 digits per code unit,
+// as expected by the gateway for LanguageTypeUnicode messages.
+// Characters outside of the Basic Multilingual Plane, such as emoji, are encoded as surrogate pairs.
+func EncodeUnicodeMessage(message string) string {
+	units := utf16.Encode([]rune(message))
+
+	var b strings.Builder
+	b.Grow(len(units) * 4)
+	for _, u := range units {
+		b.WriteByte(hexDigits[u>>12&0xF])
+		b.WriteByte(hexDigits[u>>8&0xF])
+		b.WriteByte(hexDigits[u>>4&0xF])
+		b.WriteByte(hexDigits[u&0xF])
+	}
+	return b.String()
+}
+
+// DecodeUnicodeMessage decodes a UTF-16BE hexadecimal message, reversing EncodeUnicodeMessage.
+// Unpaired surrogates are decoded as the Unicode replacement character U+FFFD.
+func DecodeUnicodeMessage(message string) (string, error) {
+	if len(message)%4 != 0 {
+		return "", errors.New("DecodeUnicodeMessage: Error: message length must be a multiple of 4")
+	}
+
+	units := make([]uint16, 0, len(message)/4)
+	for i := 0; i < len(message); i += 4 {
+		u, err := strconv.ParseUint(message[i:i+4], 16, 16)
+		if err != nil {
+			return "", errors.Errorf("DecodeUnicodeMessage: Error: invalid hexadecimal code unit %q", message[i:i+4])
+		}
+		units = append(units, uint16(u))
+	}
+	return string(utf16.Decode(units)), nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms_test
+
+import (
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeUnicodeMessage(t *testing.T) {
+	tests := []struct {
+		desc     string
+		input    string
+		expected string
+	}{
+		{
+			desc:     "With empty message",
+			input:    "",
+			expected: "",
+		},
+		{
+			desc:     "With ASCII characters",
+			input:    "Hi!",
+			expected: "004800690021",
+		},
+		{
+			desc:     "With Basic Multilingual Plane characters",
+			input:    "é€",
+			expected: "00E920AC",
+		},
+		{
+			desc:     "With CJK characters",
+			input:    "Hello, 世界",
+			expected: "00480065006C006C006F002C00204E16754C",
+		},
+		{
+			desc:     "With emoji",
+			input:    "😀",
+			expected: "D83DDE00",
+		},
+		{
+			desc:     "With astral CJK character",
+			input:    "𠀋",
+			expected: "D840DC0B",
+		},
+		{
+			desc:     "With combining sequence",
+			input:    "é",
+			expected: "00650301",
+		},
+		{
+			desc:     "With emoji ZWJ sequence",
+			input:    "👩‍💻",
+			expected: "D83DDC69200DD83DDCBB",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, owsms.EncodeUnicodeMessage(test.input))
+		})
+	}
+}
+
+func TestDecodeUnicodeMessage(t *testing.T) {
+	tests := []struct {
+		desc     string
+		input    string
+		expected string
+		err      string
+	}{
+		{
+			desc:     "With ASCII characters",
+			input:    "004800690021",
+			expected: "Hi!",
+		},
+		{
+			desc:     "With CJK characters",
+			input:    "00480065006C006C006F002C00204E16754C",
+			expected: "Hello, 世界",
+		},
+		{
+			desc:     "With lowercase hexadecimal",
+			input:    "4e16754c",
+			expected: "世界",
+		},
+		{
+			desc:     "With surrogate pair",
+			input:    "D83DDE00",
+			expected: "😀",
+		},
+		{
+			desc:     "With combining sequence",
+			input:    "00650301",
+			expected: "é",
+		},
+		{
+			desc:     "With unpaired surrogate",
+			input:    "D83D0041",
+			expected: "�A",
+		},
+		{
+			desc:  "With invalid length",
+			input: "004",
+			err:   "DecodeUnicodeMessage: Error: message length must be a multiple of 4",
+		},
+		{
+			desc:  "With invalid hexadecimal",
+			input: "00ZZ",
+			err:   `DecodeUnicodeMessage: Error: invalid hexadecimal code unit "00ZZ"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			actual, err := owsms.DecodeUnicodeMessage(test.input)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestUnicodeMessageRoundTrip(t *testing.T) {
+	for _, message := range []string{"Hello, 世界", "Café ☕ 😀👍🏽", "𝄞 music", "ñandú"} {
+		decoded, err := owsms.DecodeUnicodeMessage(owsms.EncodeUnicodeMessage(message))
+		assert.NoError(t, err)
+		assert.Equal(t, message, decoded)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+// gsm7BasicCharset GSM 03.38 default alphabet, excluding the escape character.
+const gsm7BasicCharset = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7ExtensionCharset GSM 03.38 extension table characters, each encoded as an escape sequence of 2 septets.
+const gsm7ExtensionCharset = "\f^{}\\[~]|€"
+
+var (
+	gsm7Basic     = make(map[rune]bool)
+	gsm7Extension = make(map[rune]bool)
+)
+
+func init() {
+	for _, r := range gsm7BasicCharset {
+		gsm7Basic[r] = true
+	}
+	for _, r := range gsm7ExtensionCharset {
+		gsm7Extension[r] = true
+	}
+}
+
+// IsGSM7 reports whether every character of the message is representable in the GSM 03.38
+// default alphabet or its extension table, in which case it can be sent as LanguageTypeNormal.
+func IsGSM7(message string) bool {
+	for _, r := range message {
+		if !gsm7Basic[r] && !gsm7Extension[r] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsGSM7Extension reports whether the character belongs to the GSM 03.38 extension table,
+// thus counting as 2 characters towards the message length.
+func IsGSM7Extension(r rune) bool {
+	return gsm7Extension[r]
+}
+
+// DetectLanguageType returns LanguageTypeNormal when the message is representable in GSM 03.38,
+// and LanguageTypeUnicode otherwise. Used to set the language type of send SMS requests not defining one.
+func DetectLanguageType(message string) LanguageType {
+	if IsGSM7(message) {
+		return LanguageTypeNormal
+	}
+	return LanguageTypeUnicode
+}
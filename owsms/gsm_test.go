@@ -0,0 +1,81 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms_test
+
+import (
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLanguageType(t *testing.T) {
+	tests := []struct {
+		desc     string
+		input    string
+		expected owsms.LanguageType
+	}{
+		{
+			desc:     "With ASCII characters",
+			input:    "Hello World",
+			expected: owsms.LanguageTypeNormal,
+		},
+		{
+			desc:     "With accented characters outside of GSM 03.38",
+			input:    "¿Qué? Ñandú",
+			expected: owsms.LanguageTypeUnicode,
+		},
+		{
+			desc:     "With GSM 03.38 accented characters",
+			input:    "Café £5 señor ÄÖÜ äöü à è ù ì ò Ç",
+			expected: owsms.LanguageTypeNormal,
+		},
+		{
+			desc:     "With GSM 03.38 extension characters",
+			input:    "Price: €10 [50% off] {promo} ~ ^ | \\",
+			expected: owsms.LanguageTypeNormal,
+		},
+		{
+			desc:     "With Greek capital letters",
+			input:    "ΔΦΓΛΩΠΨΣΘΞ",
+			expected: owsms.LanguageTypeNormal,
+		},
+		{
+			desc:     "With backtick",
+			input:    "`code`",
+			expected: owsms.LanguageTypeUnicode,
+		},
+		{
+			desc:     "With CJK characters",
+			input:    "Hello, 世界",
+			expected: owsms.LanguageTypeUnicode,
+		},
+		{
+			desc:     "With emoji",
+			input:    "Hi 😀",
+			expected: owsms.LanguageTypeUnicode,
+		},
+		{
+			desc:     "With escape character",
+			input:    "\x1b",
+			expected: owsms.LanguageTypeUnicode,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, owsms.DetectLanguageType(test.input))
+			assert.Equal(t, test.expected == owsms.LanguageTypeNormal, owsms.IsGSM7(test.input))
+		})
+	}
+}
+
+func TestIsGSM7Extension(t *testing.T) {
+	for _, r := range "€[]{}~^|\\\f" {
+		assert.True(t, owsms.IsGSM7Extension(r), string(r))
+	}
+	for _, r := range "aé£@ 世" {
+		assert.False(t, owsms.IsGSM7Extension(r), string(r))
+	}
+}
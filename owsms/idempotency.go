@@ -0,0 +1,230 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/internal/fileutil"
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+)
+
+// IdempotencyStore stores the mobile terminating IDs of sent SMS by idempotency key,
+// so that a repeated send SMS request with the same key returns the original result instead of sending again.
+// Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Get returns the mobile terminating IDs stored for the key, reporting whether an unexpired entry was found.
+	Get(key string) ([]int, bool, error)
+
+	// Set stores the mobile terminating IDs for the key until the TTL elapses.
+	Set(key string, mtIDs []int, ttl time.Duration) error
+}
+
+// idempotencyEntry idempotency store entry structure.
+type idempotencyEntry struct {
+	MTIDs     []int     `json:"mtids"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (e idempotencyEntry) expired(now time.Time) bool {
+	return !now.Before(e.ExpiresAt)
+}
+
+// MemoryIdempotencyStore in-memory idempotency store. Entries are lost when the process exits.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]idempotencyEntry
+}
+
+// NewMemoryIdempotencyStore initializes a new in-memory idempotency store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries: make(map[string]idempotencyEntry),
+	}
+}
+
+// Get returns the mobile terminating IDs stored for the key.
+func (s *MemoryIdempotencyStore) Get(key string) ([]int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || e.expired(time.Now()) {
+		return nil, false, nil
+	}
+	return append([]int(nil), e.MTIDs...), true, nil
+}
+
+// Set stores the mobile terminating IDs for the key until the TTL elapses.
+func (s *MemoryIdempotencyStore) Set(key string, mtIDs []int, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	pruneIdempotencyEntries(s.entries, now)
+	s.entries[key] = idempotencyEntry{
+		MTIDs:     append([]int(nil), mtIDs...),
+		ExpiresAt: now.Add(ttl),
+	}
+	return nil
+}
+
+// FileIdempotencyStore file-backed idempotency store. Entries are persisted as JSON to the file,
+// allowing them to survive process restarts. The file must not be shared between processes.
+type FileIdempotencyStore struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]idempotencyEntry
+}
+
+// NewFileIdempotencyStore initializes a new file-backed idempotency store, loading existing entries from path if it exists.
+func NewFileIdempotencyStore(path string) (*FileIdempotencyStore, error) {
+	s := &FileIdempotencyStore{
+		path:    path,
+		entries: make(map[string]idempotencyEntry),
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &s.entries); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Get returns the mobile terminating IDs stored for the key.
+func (s *FileIdempotencyStore) Get(key string) ([]int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || e.expired(time.Now()) {
+		return nil, false, nil
+	}
+	return append([]int(nil), e.MTIDs...), true, nil
+}
+
+// Set stores the mobile terminating IDs for the key until the TTL elapses and persists the store to its file.
+func (s *FileIdempotencyStore) Set(key string, mtIDs []int, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	pruneIdempotencyEntries(s.entries, now)
+	s.entries[key] = idempotencyEntry{
+		MTIDs:     append([]int(nil), mtIDs...),
+		ExpiresAt: now.Add(ttl),
+	}
+	return s.save()
+}
+
+// save atomically replaces the store's file with the current entries.
+func (s *FileIdempotencyStore) save() error {
+	b, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+
+	return fileutil.WriteFileAtomic(s.path, b)
+}
+
+// batchIdempotencyKey returns the key the results of a batch of recipients are stored by, when other batches
+// of the request with the idempotency key failed.
+func batchIdempotencyKey(key string, mobileNo []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(mobileNo, ",")))
+	return key + "/batch/" + hex.EncodeToString(sum[:])
+}
+
+// encodeBatchResults encodes the results of a batch as the MTID of each recipient sent to,
+// or the negative gateway code of each recipient rejected.
+func encodeBatchResults(results []SendSMSResult) []int {
+	codes := make([]int, len(results))
+	for i, result := range results {
+		var apiErr *owerr.APIError
+		switch {
+		case result.Err == nil:
+			codes[i] = result.MTID
+		case errors.As(result.Err, &apiErr):
+			codes[i] = apiErr.Details().GatewayCode
+		}
+	}
+	return codes
+}
+
+// decodeBatchResults decodes the results of a batch encoded by encodeBatchResults.
+func decodeBatchResults(mobileNo []string, codes []int) []SendSMSResult {
+	results := make([]SendSMSResult, len(mobileNo))
+	for i, code := range codes {
+		results[i].MobileNo = mobileNo[i]
+		if code > 0 {
+			results[i].MTID = code
+			continue
+		}
+		results[i].Err = sendSMSError(code, 0, owerr.Details{})
+	}
+	return results
+}
+
+func pruneIdempotencyEntries(entries map[string]idempotencyEntry, now time.Time) {
+	for k, e := range entries {
+		if e.expired(now) {
+			delete(entries, k)
+		}
+	}
+}
+
+// keyedMutex serializes operations sharing the same key.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (m *keyedMutex) Lock(key string) {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = make(map[string]*keyedLock)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.Lock()
+}
+
+func (m *keyedMutex) Unlock(key string) {
+	m.mu.Lock()
+	l := m.locks[key]
+	l.refs--
+	if l.refs == 0 {
+		delete(m.locks, key)
+	}
+	m.mu.Unlock()
+
+	l.Unlock()
+}
@@ -0,0 +1,286 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCountingServer(calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		fmt.Fprintln(w, 145712467+n)
+	}))
+}
+
+func TestSendSMSIdempotency(t *testing.T) {
+	t.Run("With repeated idempotency key", func(t *testing.T) {
+		var calls int32
+		ts := newCountingServer(&calls)
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithIdempotencyStore(owsms.NewMemoryIdempotencyStore(), time.Minute),
+		)
+
+		input := &owsms.SendSMSInput{
+			Message:        "Your OTP is 123456",
+			MobileNo:       []string{"60123456789"},
+			IdempotencyKey: "otp-1",
+		}
+		output, resp, err := svc.SendSMS(input)
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+		assert.Equal(t, []int{145712468}, output.MTIDs)
+
+		output, resp, err = svc.SendSMS(input)
+		assert.NoError(t, err)
+		assert.Nil(t, resp)
+		assert.Equal(t, []int{145712468}, output.MTIDs)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("With different idempotency keys", func(t *testing.T) {
+		var calls int32
+		ts := newCountingServer(&calls)
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithIdempotencyStore(owsms.NewMemoryIdempotencyStore(), time.Minute),
+		)
+
+		for _, key := range []string{"otp-1", "otp-2", ""} {
+			_, _, err := svc.SendSMS(&owsms.SendSMSInput{
+				Message:        "Your OTP is 123456",
+				MobileNo:       []string{"60123456789"},
+				IdempotencyKey: key,
+			})
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("With expired idempotency key", func(t *testing.T) {
+		var calls int32
+		ts := newCountingServer(&calls)
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithIdempotencyStore(owsms.NewMemoryIdempotencyStore(), 10*time.Millisecond),
+		)
+
+		input := &owsms.SendSMSInput{
+			Message:        "Your OTP is 123456",
+			MobileNo:       []string{"60123456789"},
+			IdempotencyKey: "otp-1",
+		}
+		_, _, err := svc.SendSMS(input)
+		assert.NoError(t, err)
+
+		time.Sleep(20 * time.Millisecond)
+
+		output, _, err := svc.SendSMS(input)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{145712469}, output.MTIDs)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("With non-positive TTL", func(t *testing.T) {
+		var calls int32
+		ts := newCountingServer(&calls)
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithIdempotencyStore(owsms.NewMemoryIdempotencyStore(), 0),
+		)
+
+		input := &owsms.SendSMSInput{
+			Message:        "Your OTP is 123456",
+			MobileNo:       []string{"60123456789"},
+			IdempotencyKey: "otp-1",
+		}
+		_, _, err := svc.SendSMS(input)
+		assert.NoError(t, err)
+		output, _, err := svc.SendSMS(input)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{145712468}, output.MTIDs)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("With failed send", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				fmt.Fprintln(w, "-600")
+				return
+			}
+			fmt.Fprintln(w, "145712468")
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithIdempotencyStore(owsms.NewMemoryIdempotencyStore(), time.Minute),
+		)
+
+		input := &owsms.SendSMSInput{
+			Message:        "Your OTP is 123456",
+			MobileNo:       []string{"60123456789"},
+			IdempotencyKey: "otp-1",
+		}
+		_, _, err := svc.SendSMS(input)
+		assert.Error(t, err)
+
+		output, _, err := svc.SendSMS(input)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{145712468}, output.MTIDs)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("With failed batch", func(t *testing.T) {
+		var (
+			mu    sync.Mutex
+			sent  []string
+			fails = 1
+		)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			mobileNo := r.URL.Query().Get("mobileno")
+			if mobileNo == "60120000003,60120000004" && fails > 0 {
+				fails--
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			sent = append(sent, mobileNo)
+			switch mobileNo {
+			case "60120000001,60120000002":
+				fmt.Fprintln(w, "145712461,-300")
+			case "60120000003,60120000004":
+				fmt.Fprintln(w, "145712463,145712464")
+			}
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithBatchSize(2),
+			owsms.WithIdempotencyStore(owsms.NewMemoryIdempotencyStore(), time.Minute),
+		)
+
+		input := &owsms.SendSMSInput{
+			Message:        "Hello World",
+			MobileNo:       []string{"60120000001", "60120000002", "60120000003", "60120000004"},
+			IdempotencyKey: "campaign-1",
+		}
+		output, _, err := svc.SendSMS(input)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{145712461}, output.MTIDs)
+		assert.True(t, errors.Is(output.Results[2].Err, owerr.ErrRequestFailure))
+
+		// Only the failed batch is sent again.
+		output, _, err = svc.SendSMS(input)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{145712461, 145712463, 145712464}, output.MTIDs)
+		assert.True(t, errors.Is(output.Results[1].Err, owerr.ErrInvalidMobileNo))
+		assert.Equal(t, []string{"60120000001,60120000002", "60120000003,60120000004"}, sent)
+
+		output, resp, err := svc.SendSMS(input)
+		assert.NoError(t, err)
+		assert.Nil(t, resp)
+		assert.Equal(t, []int{145712461, 145712463, 145712464}, output.MTIDs)
+		assert.Len(t, sent, 2)
+	})
+
+	t.Run("With concurrent repeated idempotency key", func(t *testing.T) {
+		var calls int32
+		ts := newCountingServer(&calls)
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithIdempotencyStore(owsms.NewMemoryIdempotencyStore(), time.Minute),
+		)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+					Message:        "Your OTP is 123456",
+					MobileNo:       []string{"60123456789"},
+					IdempotencyKey: "otp-1",
+				})
+				assert.NoError(t, err)
+				assert.Equal(t, []int{145712468}, output.MTIDs)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestFileIdempotencyStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "owsms")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "idempotency.json")
+
+	t.Run("With missing file", func(t *testing.T) {
+		store, err := owsms.NewFileIdempotencyStore(path)
+		assert.NoError(t, err)
+
+		_, ok, err := store.Get("otp-1")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("With entries persisted across instances", func(t *testing.T) {
+		store, err := owsms.NewFileIdempotencyStore(path)
+		assert.NoError(t, err)
+		assert.NoError(t, store.Set("otp-1", []int{145712468, 145712469}, time.Minute))
+		assert.NoError(t, store.Set("otp-2", []int{145712470}, -time.Minute))
+
+		store, err = owsms.NewFileIdempotencyStore(path)
+		assert.NoError(t, err)
+
+		mtIDs, ok, err := store.Get("otp-1")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []int{145712468, 145712469}, mtIDs)
+
+		_, ok, err = store.Get("otp-2")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("With corrupted file", func(t *testing.T) {
+		corrupted := filepath.Join(dir, "corrupted.json")
+		assert.NoError(t, ioutil.WriteFile(corrupted, []byte("{"), 0600))
+
+		_, err := owsms.NewFileIdempotencyStore(corrupted)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+import (
+	"time"
+)
+
+// Option configures a Client initialized with New.
+type Option func(*Client)
+
+// Logger logs the requests made by the client. *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// WithBaseURL sets the OneWaySMS API gateway base URL.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithCredentials sets the API Username and API Password used to authenticate with the gateway.
+func WithCredentials(apiUsername, apiPassword string) Option {
+	return func(c *Client) {
+		c.apiUsername = apiUsername
+		c.apiPassword = apiPassword
+	}
+}
+
+// WithSenderID sets the Sender ID displayed to the recipients.
+func WithSenderID(senderID string) Option {
+	return func(c *Client) {
+		c.senderID = senderID
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to perform requests. Defaults to http.DefaultClient.
+func WithHTTPClient(client Doer) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout sets the timeout of each request made to the gateway. Zero means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithLogger sets the logger used to log requests made to the gateway. Credentials are never logged.
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry requests failing with transient errors. Defaults to no retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithIdempotencyStore sets the store used to deduplicate send SMS requests having an IdempotencyKey.
+// Outputs are kept for the TTL provided, or 24h when it is not positive.
+func WithIdempotencyStore(store IdempotencyStore, ttl time.Duration) Option {
+	return func(c *Client) {
+		if ttl <= 0 {
+			ttl = defaultIdempotencyTTL
+		}
+		c.idempotencyStore = store
+		c.idempotencyTTL = ttl
+	}
+}
+
+// WithPhoneNormalization normalizes the MobileNo of send SMS inputs before sending, see phone.Normalize.
+// Numbers in national format are prefixed with the calling code of the default region, such as MY or SG.
+// Duplicate numbers are only sent once and share the same result, and invalid numbers are not sent and reported
+// with an owerr.ValidationError in their result. The request fails when every number is invalid.
+func WithPhoneNormalization(defaultRegion string) Option {
+	return func(c *Client) {
+		c.normalizePhone = true
+		c.phoneRegion = defaultRegion
+	}
+}
+
+// WithSuppressionList sets the suppression list consulted before sending SMS. Suppressed recipients, matched after
+// phone normalization when enabled, are not sent to and reported with an owerr.RecipientSuppressed error in their
+// result. The request fails when every recipient is suppressed or invalid.
+func WithSuppressionList(list SuppressionList) Option {
+	return func(c *Client) {
+		c.suppressionList = list
+	}
+}
+
+// WithBatchSize splits the recipients of send SMS requests into batches of at most size numbers,
+// keeping request URLs within the gateway's length limits. Defaults to 0, sending every recipient in a single request.
+func WithBatchSize(size int) Option {
+	return func(c *Client) {
+		c.batchSize = size
+	}
+}
+
+// WithBatchConcurrency sets the maximum number of batches of a send SMS request sent concurrently. Defaults to 1.
+func WithBatchConcurrency(concurrency int) Option {
+	return func(c *Client) {
+		c.batchConcurrency = concurrency
+	}
+}
+
+// WithLookupConcurrency sets the maximum number of transaction status lookups of CheckTransactionStatuses made
+// concurrently. Defaults to 4.
+func WithLookupConcurrency(concurrency int) Option {
+	return func(c *Client) {
+		c.lookupConcurrency = concurrency
+	}
+}
+
+// WithSendRateLimiter sets the rate limiter throttling send SMS requests, each batch counting as a request.
+func WithSendRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.sendLimiter = limiter
+	}
+}
+
+// WithQueryRateLimiter sets the rate limiter throttling transaction status and credit balance requests,
+// separately from send SMS requests.
+func WithQueryRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.queryLimiter = limiter
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingDoer struct {
+	calls int
+}
+
+func (d *countingDoer) Do(req *http.Request) (*http.Response, error) {
+	d.calls++
+	return http.DefaultClient.Do(req)
+}
+
+type bufferLogger struct {
+	lines []string
+}
+
+func (l *bufferLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestNew(t *testing.T) {
+	t.Run("With configuration options", func(t *testing.T) {
+		var r *http.Request
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			r = req
+			fmt.Fprintln(w, "145712468")
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithCredentials("Username", "Password"),
+			owsms.WithSenderID("SenderID"),
+		)
+
+		_, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "/api.aspx", r.URL.Path)
+		assert.Equal(t, "Username", r.URL.Query().Get("apiusername"))
+		assert.Equal(t, "Password", r.URL.Query().Get("apipassword"))
+		assert.Equal(t, "SenderID", r.URL.Query().Get("senderid"))
+		assert.True(t, strings.HasPrefix(r.UserAgent(), "onewaysms-sdk-go/"))
+	})
+
+	t.Run("With custom HTTP client and user agent", func(t *testing.T) {
+		var userAgent string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userAgent = r.UserAgent()
+			fmt.Fprintln(w, "6500.5")
+		}))
+		defer ts.Close()
+
+		doer := &countingDoer{}
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithHTTPClient(doer),
+			owsms.WithUserAgent("custom-agent/1.0"),
+		)
+
+		_, _, err := svc.CheckCreditBalance()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, doer.calls)
+		assert.Equal(t, "custom-agent/1.0", userAgent)
+	})
+
+	t.Run("With timeout", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithTimeout(50*time.Millisecond),
+		)
+
+		output, _, err := svc.CheckCreditBalance()
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Nil(t, output)
+	})
+
+	t.Run("With timeout longer than response", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, "6500.5")
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithTimeout(time.Second),
+		)
+
+		output, _, err := svc.CheckCreditBalance()
+		assert.NoError(t, err)
+		assert.Equal(t, float32(6500.5), output.CreditBalance)
+	})
+
+	t.Run("With logger", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, "6500.5")
+		}))
+		defer ts.Close()
+
+		logger := &bufferLogger{}
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithCredentials("Username", "Secret"),
+			owsms.WithLogger(logger),
+		)
+
+		_, _, err := svc.CheckCreditBalance()
+		assert.NoError(t, err)
+		assert.Len(t, logger.lines, 1)
+		assert.Contains(t, logger.lines[0], "/bulkcredit.aspx")
+		assert.NotContains(t, logger.lines[0], "Secret")
+	})
+
+	t.Run("With logger and network error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		ts.Close()
+
+		logger := &bufferLogger{}
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithCredentials("Username", "S3CRET"),
+			owsms.WithLogger(logger),
+		)
+
+		_, _, err := svc.CheckCreditBalance()
+		assert.Error(t, err)
+		assert.Len(t, logger.lines, 1)
+		assert.Contains(t, logger.lines[0], "/bulkcredit.aspx")
+		assert.Contains(t, logger.lines[0], "failed")
+		assert.NotContains(t, logger.lines[0], "S3CRET")
+		assert.NotContains(t, logger.lines[0], "Username")
+	})
+}
+
+func TestWithPhoneNormalization(t *testing.T) {
+	t.Run("With numbers in different formats", func(t *testing.T) {
+		var mobileNo string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mobileNo = r.URL.Query().Get("mobileno")
+			fmt.Fprintln(w, "145712468,145712469")
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithPhoneNormalization("MY"))
+
+		input := &owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"+60 12-345 6789", "012-3456789", "0129876543"},
+		}
+		output, _, err := svc.SendSMS(input)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{145712468, 145712469}, output.MTIDs)
+		assert.Equal(t, "60123456789,60129876543", mobileNo)
+		assert.Equal(t, []owsms.SendSMSResult{
+			{MobileNo: "+60 12-345 6789", MTID: 145712468},
+			{MobileNo: "012-3456789", MTID: 145712468},
+			{MobileNo: "0129876543", MTID: 145712469},
+		}, output.Results)
+		assert.Equal(t, []string{"+60 12-345 6789", "012-3456789", "0129876543"}, input.MobileNo)
+	})
+
+	t.Run("With invalid numbers", func(t *testing.T) {
+		var mobileNo string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mobileNo = r.URL.Query().Get("mobileno")
+			fmt.Fprintln(w, "145712468")
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithPhoneNormalization("MY"))
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"012-3456789", "invalid", "+60123456789"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "60123456789", mobileNo)
+		assert.Equal(t, []int{145712468}, output.MTIDs)
+		assert.Len(t, output.Results, 3)
+		assert.Equal(t, owsms.SendSMSResult{MobileNo: "012-3456789", MTID: 145712468}, output.Results[0])
+		assert.Equal(t, owsms.SendSMSResult{MobileNo: "+60123456789", MTID: 145712468}, output.Results[2])
+		assert.Equal(t, "invalid", output.Results[1].MobileNo)
+		assert.EqualError(t, output.Results[1].Err, `SendSMSInput: Error: MobileNo "invalid" is invalid: unexpected character 'i'`)
+		vErr, ok := output.Results[1].Err.(owerr.ValidationError)
+		assert.True(t, ok)
+		assert.Equal(t, "MobileNo", vErr.Field())
+	})
+
+	t.Run("With every number invalid", func(t *testing.T) {
+		var called bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithPhoneNormalization("MY"))
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"invalid", "123"},
+		})
+		assert.EqualError(t, err, `SendSMSInput: Error: MobileNo "invalid" is invalid: unexpected character 'i'`)
+		_, ok := err.(owerr.ValidationError)
+		assert.True(t, ok)
+		assert.False(t, called)
+		assert.Empty(t, output.MTIDs)
+		assert.Len(t, output.Results, 2)
+		assert.EqualError(t, output.Results[1].Err, `SendSMSInput: Error: MobileNo "123" is invalid: invalid length for region MY`)
+	})
+}
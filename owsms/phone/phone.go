@@ -0,0 +1,177 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package phone normalizes and validates phone numbers into the digits-with-country-code form
+// expected by the OneWaySMS API gateway, for example 60123456789.
+package phone
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	minLength = 8  // Minimum length of a normalized number, including the country code.
+	maxLength = 15 // Maximum length of a normalized number, including the country code, as defined by E.164.
+)
+
+// region country calling code and national number rules of a region.
+type region struct {
+	callingCode string
+	trunkPrefix string // Prefix dialled before national numbers within the region, if any.
+	minNational int    // Minimum length of national significant numbers.
+	maxNational int    // Maximum length of national significant numbers.
+}
+
+// regions supported default regions, keyed by ISO 3166-1 alpha-2 code.
+var regions = map[string]region{
+	"AU": {callingCode: "61", trunkPrefix: "0", minNational: 9, maxNational: 9},
+	"BN": {callingCode: "673", minNational: 7, maxNational: 7},
+	"CA": {callingCode: "1", trunkPrefix: "1", minNational: 10, maxNational: 10},
+	"CN": {callingCode: "86", trunkPrefix: "0", minNational: 9, maxNational: 11},
+	"GB": {callingCode: "44", trunkPrefix: "0", minNational: 9, maxNational: 10},
+	"HK": {callingCode: "852", minNational: 8, maxNational: 8},
+	"ID": {callingCode: "62", trunkPrefix: "0", minNational: 8, maxNational: 12},
+	"IN": {callingCode: "91", trunkPrefix: "0", minNational: 10, maxNational: 10},
+	"JP": {callingCode: "81", trunkPrefix: "0", minNational: 9, maxNational: 10},
+	"KR": {callingCode: "82", trunkPrefix: "0", minNational: 8, maxNational: 10},
+	"MO": {callingCode: "853", minNational: 8, maxNational: 8},
+	"MY": {callingCode: "60", trunkPrefix: "0", minNational: 8, maxNational: 10},
+	"NZ": {callingCode: "64", trunkPrefix: "0", minNational: 8, maxNational: 10},
+	"PH": {callingCode: "63", trunkPrefix: "0", minNational: 8, maxNational: 10},
+	"SG": {callingCode: "65", minNational: 8, maxNational: 8},
+	"TH": {callingCode: "66", trunkPrefix: "0", minNational: 8, maxNational: 9},
+	"TW": {callingCode: "886", trunkPrefix: "0", minNational: 8, maxNational: 9},
+	"US": {callingCode: "1", trunkPrefix: "1", minNational: 10, maxNational: 10},
+	"VN": {callingCode: "84", trunkPrefix: "0", minNational: 9, maxNational: 10},
+}
+
+// Error invalid phone number error.
+type Error struct {
+	Number string // Phone number as provided.
+	Reason string // Reason the phone number is invalid.
+}
+
+// Error returns the string representation of the error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("phone: invalid number %q: %s", e.Number, e.Reason)
+}
+
+// SupportedRegion reports whether the region can be used as a default region.
+func SupportedRegion(regionCode string) bool {
+	_, ok := regions[strings.ToUpper(regionCode)]
+	return ok
+}
+
+// Normalize normalizes the phone number into digits prefixed with the country calling code, for example 60123456789.
+// Spaces, dashes, dots and parentheses are ignored. Numbers in international format, starting with + or 00,
+// are kept as is. Numbers in national format are prefixed with the calling code of the default region,
+// an ISO 3166-1 alpha-2 code such as MY or SG, after removing the region's trunk prefix.
+// An empty default region requires numbers to be in international format.
+// Returns an *Error when the number is invalid.
+func Normalize(number, defaultRegion string) (string, error) {
+	invalid := func(reason string) (string, error) {
+		return "", &Error{Number: number, Reason: reason}
+	}
+
+	s := strings.TrimSpace(number)
+	if s == "" {
+		return invalid("number is empty")
+	}
+
+	international := strings.HasPrefix(s, "+")
+	if international {
+		s = s[1:]
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return invalid(fmt.Sprintf("unexpected character %q", r))
+		}
+	}
+	digits := b.String()
+
+	if !international && strings.HasPrefix(digits, "00") {
+		international = true
+		digits = digits[2:]
+	}
+
+	if !international {
+		if defaultRegion == "" {
+			return invalid("country code is missing")
+		}
+		rg, ok := regions[strings.ToUpper(defaultRegion)]
+		if !ok {
+			return invalid(fmt.Sprintf("unsupported default region %q", defaultRegion))
+		}
+		switch {
+		case rg.trunkPrefix != "" && strings.HasPrefix(digits, rg.trunkPrefix) && rg.validNational(digits[len(rg.trunkPrefix):]):
+			digits = rg.callingCode + digits[len(rg.trunkPrefix):]
+		case strings.HasPrefix(digits, rg.callingCode) && rg.validNational(digits[len(rg.callingCode):]):
+			// Already prefixed with the region's calling code, without the +.
+		case rg.validNational(digits):
+			digits = rg.callingCode + digits
+		default:
+			return invalid(fmt.Sprintf("invalid length for region %s", strings.ToUpper(defaultRegion)))
+		}
+	}
+
+	if len(digits) < minLength || len(digits) > maxLength {
+		return invalid(fmt.Sprintf("length must be between %d and %d digits", minLength, maxLength))
+	}
+	if digits[0] == '0' {
+		return invalid("country code must not start with 0")
+	}
+	if rg, ok := regionByCallingCode(digits); ok && !rg.validNational(digits[len(rg.callingCode):]) {
+		return invalid(fmt.Sprintf("invalid length for country code %s", rg.callingCode))
+	}
+	return digits, nil
+}
+
+// NormalizeAll normalizes the phone numbers, see Normalize, removing duplicates while preserving order.
+// Invalid numbers are left out of the normalized numbers and returned as *Error, one per invalid number.
+func NormalizeAll(numbers []string, defaultRegion string) ([]string, []error) {
+	var (
+		normalized []string
+		errs       []error
+		seen       = make(map[string]bool, len(numbers))
+	)
+	for _, number := range numbers {
+		n, err := Normalize(number, defaultRegion)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		normalized = append(normalized, n)
+	}
+	return normalized, errs
+}
+
+func (rg region) validNational(national string) bool {
+	return len(national) >= rg.minNational && len(national) <= rg.maxNational && !strings.HasPrefix(national, "0")
+}
+
+// regionByCallingCode returns the rules of the region whose calling code prefixes the normalized number.
+func regionByCallingCode(digits string) (region, bool) {
+	for n := 3; n >= 1; n-- {
+		if len(digits) <= n {
+			continue
+		}
+		for _, rg := range regions {
+			if rg.callingCode == digits[:n] {
+				return rg, true
+			}
+		}
+	}
+	return region{}, false
+}
@@ -0,0 +1,153 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package phone_test
+
+import (
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/phone"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		desc          string
+		number        string
+		defaultRegion string
+		expected      string
+		err           string
+	}{
+		{
+			desc:     "With international format",
+			number:   "+60 12-345 6789",
+			expected: "60123456789",
+		},
+		{
+			desc:     "With international prefix",
+			number:   "0060123456789",
+			expected: "60123456789",
+		},
+		{
+			desc:     "With parentheses and dots",
+			number:   "+1 (415) 555.2671",
+			expected: "14155552671",
+		},
+		{
+			desc:          "With national format and trunk prefix",
+			number:        "012-3456789",
+			defaultRegion: "MY",
+			expected:      "60123456789",
+		},
+		{
+			desc:          "With national format without trunk prefix",
+			number:        "8123 4567",
+			defaultRegion: "SG",
+			expected:      "6581234567",
+		},
+		{
+			desc:          "With lowercase default region",
+			number:        "8123 4567",
+			defaultRegion: "sg",
+			expected:      "6581234567",
+		},
+		{
+			desc:          "With calling code without plus sign",
+			number:        "6581234567",
+			defaultRegion: "SG",
+			expected:      "6581234567",
+		},
+		{
+			desc:          "With calling code without plus sign and trunk prefix region",
+			number:        "60123456789",
+			defaultRegion: "MY",
+			expected:      "60123456789",
+		},
+		{
+			desc:          "With international format and different default region",
+			number:        "+65 8123 4567",
+			defaultRegion: "MY",
+			expected:      "6581234567",
+		},
+		{
+			desc:   "With empty number",
+			number: "  ",
+			err:    `phone: invalid number "  ": number is empty`,
+		},
+		{
+			desc:   "With letters",
+			number: "+60 12-ABC 6789",
+			err:    `phone: invalid number "+60 12-ABC 6789": unexpected character 'A'`,
+		},
+		{
+			desc:   "With national format and no default region",
+			number: "012-3456789",
+			err:    `phone: invalid number "012-3456789": country code is missing`,
+		},
+		{
+			desc:          "With unsupported default region",
+			number:        "012-3456789",
+			defaultRegion: "XX",
+			err:           `phone: invalid number "012-3456789": unsupported default region "XX"`,
+		},
+		{
+			desc:          "With invalid national length",
+			number:        "812345",
+			defaultRegion: "SG",
+			err:           `phone: invalid number "812345": invalid length for region SG`,
+		},
+		{
+			desc:   "With too short international number",
+			number: "+6012",
+			err:    `phone: invalid number "+6012": length must be between 8 and 15 digits`,
+		},
+		{
+			desc:   "With too long international number",
+			number: "+60123456789012345",
+			err:    `phone: invalid number "+60123456789012345": length must be between 8 and 15 digits`,
+		},
+		{
+			desc:   "With invalid length for known country code",
+			number: "+65 8123 45678",
+			err:    `phone: invalid number "+65 8123 45678": invalid length for country code 65`,
+		},
+		{
+			desc:     "With unknown country code",
+			number:   "+49 30 123456",
+			expected: "4930123456",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			actual, err := phone.Normalize(test.number, test.defaultRegion)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				_, ok := err.(*phone.Error)
+				assert.True(t, ok)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestNormalizeAll(t *testing.T) {
+	normalized, errs := phone.NormalizeAll([]string{
+		"+60 12-345 6789",
+		"012-3456789",
+		"invalid",
+		"0129876543",
+		"60123456789",
+	}, "MY")
+	assert.Equal(t, []string{"60123456789", "60129876543"}, normalized)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "invalid", errs[0].(*phone.Error).Number)
+}
+
+func TestSupportedRegion(t *testing.T) {
+	assert.True(t, phone.SupportedRegion("MY"))
+	assert.True(t, phone.SupportedRegion("sg"))
+	assert.False(t, phone.SupportedRegion("XX"))
+}
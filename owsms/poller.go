@@ -0,0 +1,170 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+)
+
+const (
+	defaultPollInterval    = 5 * time.Second
+	defaultPollMaxInterval = time.Minute
+	defaultPollConcurrency = 4
+)
+
+// StatusUpdate transaction status update structure, reported by StatusPoller.
+type StatusUpdate struct {
+	MTID   int                 // Mobile terminating ID.
+	Status MTTransactionStatus // Latest status of the mobile terminating transaction. Empty when no status was obtained yet.
+	Code   int                 // Raw status code of the latest status returned by the gateway.
+	Err    error               // Error ending the polling of the MTID, such as context.DeadlineExceeded on timeout.
+	Final  bool                // Whether the MTID reached a final state and is no longer polled.
+}
+
+// StatusPoller polls the transaction status of many mobile terminating IDs until each reaches a final state:
+// MTTransactionStatusSuccess, MTTransactionStatusFailed, MTTransactionStatusNotFound, or the timeout.
+// Lookups failing with errors, such as network errors, are retried on the next round.
+type StatusPoller struct {
+	Client      *Client       // Client performing the transaction status lookups.
+	Interval    time.Duration // Delay before the second round of lookups, doubled on every round. Defaults to 5s.
+	MaxInterval time.Duration // Upper bound of the delay between rounds. Defaults to 1m.
+	Timeout     time.Duration // Duration after which lookups are cancelled and MTIDs still pending are reported with context.DeadlineExceeded. Zero means no timeout.
+	Concurrency int           // Maximum number of concurrent lookups. Defaults to 4.
+	Clock       Clock         // Clock used to wait between rounds. Defaults to the system clock.
+}
+
+// Poll polls the transaction status of the MTIDs, duplicates being polled once, in the background.
+// Every status transition is sent on the returned channel, the last update of each MTID having Final set.
+// The channel is closed once every MTID is final, or the context is done, in which case pending MTIDs
+// are reported with the context's error. The channel must be drained until closed.
+func (p *StatusPoller) Poll(ctx context.Context, mtIDs []int) <-chan StatusUpdate {
+	updates := make(chan StatusUpdate)
+	go p.poll(ctx, mtIDs, updates)
+	return updates
+}
+
+func (p *StatusPoller) poll(ctx context.Context, mtIDs []int, updates chan<- StatusUpdate) {
+	defer close(updates)
+
+	clock := p.Clock
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	interval := p.Interval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	maxInterval := p.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultPollMaxInterval
+	}
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	var (
+		pending []int
+		latest  = make(map[int]StatusUpdate)
+		seen    = make(map[int]bool)
+	)
+	for _, mtID := range mtIDs {
+		if !seen[mtID] {
+			seen[mtID] = true
+			pending = append(pending, mtID)
+		}
+	}
+
+	// finish reports every pending MTID as final with the error.
+	finish := func(err error) {
+		for _, mtID := range pending {
+			update := latest[mtID]
+			update.MTID, update.Err, update.Final = mtID, err, true
+			updates <- update
+		}
+	}
+
+	for len(pending) > 0 {
+		lookups := p.lookup(ctx, pending)
+
+		remaining := pending[:0]
+		for i, mtID := range pending {
+			update := lookups[i]
+			switch {
+			case update.Final:
+				updates <- update
+			case update.Err == nil && update.Status != latest[mtID].Status:
+				latest[mtID] = update
+				updates <- update
+				remaining = append(remaining, mtID)
+			default:
+				remaining = append(remaining, mtID)
+			}
+		}
+		pending = remaining
+		if len(pending) <= 0 {
+			return
+		}
+
+		if ctx.Err() != nil {
+			finish(ctx.Err())
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			finish(ctx.Err())
+			return
+		case <-clock.After(interval):
+		}
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// lookup checks the transaction status of the MTIDs concurrently, returning an update for each of them.
+func (p *StatusPoller) lookup(ctx context.Context, mtIDs []int) []StatusUpdate {
+	concurrency := p.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultPollConcurrency
+	}
+
+	lookups := make([]StatusUpdate, len(mtIDs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, mtID := range mtIDs {
+		wg.Add(1)
+		go func(i, mtID int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			update := StatusUpdate{MTID: mtID}
+			output, _, err := p.Client.LookupTransactionStatusWithContext(ctx, &CheckTransactionStatusInput{MTID: mtID})
+			if err != nil {
+				update.Err = err
+				update.Final = isInvalidParameter(err)
+			} else {
+				update.Status, update.Code = output.Status, output.Code
+				update.Final = output.Status.Final()
+			}
+			lookups[i] = update
+		}(i, mtID)
+	}
+	wg.Wait()
+	return lookups
+}
+
+// isInvalidParameter reports whether the error is an input validation error, which will not change by polling again.
+func isInvalidParameter(err error) bool {
+	return errors.Is(err, owerr.ErrInvalidParameter)
+}
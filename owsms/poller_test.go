@@ -0,0 +1,187 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStatusServer returns a server responding to each MTID with the next of its responses, repeating the last one.
+func newStatusServer(responses map[string][]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		mtID := r.URL.Query().Get("mtid")
+		queue := responses[mtID]
+		fmt.Fprintln(w, queue[0])
+		if len(queue) > 1 {
+			responses[mtID] = queue[1:]
+		}
+	}))
+}
+
+func collectUpdates(updates <-chan owsms.StatusUpdate) map[int][]owsms.StatusUpdate {
+	collected := make(map[int][]owsms.StatusUpdate)
+	for u := range updates {
+		collected[u.MTID] = append(collected[u.MTID], u)
+	}
+	return collected
+}
+
+func TestStatusPoller(t *testing.T) {
+	t.Run("With MTIDs reaching final states", func(t *testing.T) {
+		ts := newStatusServer(map[string][]string{
+			"1": {"100", "100", "0"},
+			"2": {"100", "-200"},
+			"3": {"-100"},
+			"4": {"random", "0"},
+		})
+		defer ts.Close()
+
+		poller := &owsms.StatusPoller{
+			Client:   owsms.New(owsms.WithBaseURL(ts.URL)),
+			Interval: time.Millisecond,
+		}
+		updates := collectUpdates(poller.Poll(context.Background(), []int{1, 2, 3, 4, 1}))
+
+		assert.Equal(t, []owsms.StatusUpdate{
+			{MTID: 1, Status: owsms.MTTransactionStatusTelcoDelivered, Code: 100},
+			{MTID: 1, Status: owsms.MTTransactionStatusSuccess, Code: 0, Final: true},
+		}, updates[1])
+		assert.Equal(t, []owsms.StatusUpdate{
+			{MTID: 2, Status: owsms.MTTransactionStatusTelcoDelivered, Code: 100},
+			{MTID: 2, Status: owsms.MTTransactionStatusFailed, Code: -200, Final: true},
+		}, updates[2])
+		assert.Equal(t, []owsms.StatusUpdate{
+			{MTID: 3, Status: owsms.MTTransactionStatusNotFound, Code: -100, Final: true},
+		}, updates[3])
+		assert.Equal(t, []owsms.StatusUpdate{
+			{MTID: 4, Status: owsms.MTTransactionStatusSuccess, Code: 0, Final: true},
+		}, updates[4])
+	})
+
+	t.Run("With timeout", func(t *testing.T) {
+		ts := newStatusServer(map[string][]string{
+			"1": {"100"},
+		})
+		defer ts.Close()
+
+		poller := &owsms.StatusPoller{
+			Client:   owsms.New(owsms.WithBaseURL(ts.URL)),
+			Interval: time.Millisecond,
+			Timeout:  20 * time.Millisecond,
+		}
+		updates := collectUpdates(poller.Poll(context.Background(), []int{1}))
+
+		assert.Equal(t, []owsms.StatusUpdate{
+			{MTID: 1, Status: owsms.MTTransactionStatusTelcoDelivered, Code: 100},
+			{MTID: 1, Status: owsms.MTTransactionStatusTelcoDelivered, Code: 100, Err: context.DeadlineExceeded, Final: true},
+		}, updates[1])
+	})
+
+	t.Run("With backoff between rounds", func(t *testing.T) {
+		ts := newStatusServer(map[string][]string{
+			"1": {"100", "100", "100", "0"},
+		})
+		defer ts.Close()
+
+		clock := newFakeClock()
+		poller := &owsms.StatusPoller{
+			Client:      owsms.New(owsms.WithBaseURL(ts.URL)),
+			Interval:    time.Second,
+			MaxInterval: 3 * time.Second,
+			Clock:       clock,
+		}
+		updates := poller.Poll(context.Background(), []int{1})
+		assert.Equal(t, owsms.MTTransactionStatusTelcoDelivered, (<-updates).Status)
+
+		for _, d := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+			clock.BlockUntil(1)
+			clock.Advance(d - time.Millisecond)
+			time.Sleep(5 * time.Millisecond)
+			select {
+			case u := <-updates:
+				t.Fatalf("unexpected update before backoff elapsed: %+v", u)
+			default:
+			}
+			clock.Advance(time.Millisecond)
+		}
+
+		u := <-updates
+		assert.Equal(t, owsms.StatusUpdate{MTID: 1, Status: owsms.MTTransactionStatusSuccess, Final: true}, u)
+		_, ok := <-updates
+		assert.False(t, ok)
+	})
+
+	t.Run("With cancelled context", func(t *testing.T) {
+		ts := newStatusServer(map[string][]string{
+			"1": {"100"},
+		})
+		defer ts.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		poller := &owsms.StatusPoller{
+			Client:   owsms.New(owsms.WithBaseURL(ts.URL)),
+			Interval: time.Hour,
+		}
+		updates := poller.Poll(ctx, []int{1})
+		assert.Equal(t, owsms.MTTransactionStatusTelcoDelivered, (<-updates).Status)
+		cancel()
+
+		assert.Equal(t, map[int][]owsms.StatusUpdate{
+			1: {{MTID: 1, Status: owsms.MTTransactionStatusTelcoDelivered, Code: 100, Err: context.Canceled, Final: true}},
+		}, collectUpdates(updates))
+	})
+
+	t.Run("With timeout during backoff", func(t *testing.T) {
+		ts := newStatusServer(map[string][]string{
+			"1": {"100"},
+		})
+		defer ts.Close()
+
+		poller := &owsms.StatusPoller{
+			Client:   owsms.New(owsms.WithBaseURL(ts.URL)),
+			Interval: time.Hour,
+			Timeout:  20 * time.Millisecond,
+		}
+		start := time.Now()
+		updates := collectUpdates(poller.Poll(context.Background(), []int{1}))
+
+		assert.True(t, time.Since(start) < time.Second)
+		assert.Equal(t, []owsms.StatusUpdate{
+			{MTID: 1, Status: owsms.MTTransactionStatusTelcoDelivered, Code: 100},
+			{MTID: 1, Status: owsms.MTTransactionStatusTelcoDelivered, Code: 100, Err: context.DeadlineExceeded, Final: true},
+		}, updates[1])
+	})
+
+	t.Run("With timeout during hung lookup", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer ts.Close()
+
+		poller := &owsms.StatusPoller{
+			Client:  owsms.New(owsms.WithBaseURL(ts.URL)),
+			Timeout: 20 * time.Millisecond,
+		}
+		start := time.Now()
+		updates := collectUpdates(poller.Poll(context.Background(), []int{1}))
+
+		assert.True(t, time.Since(start) < time.Second)
+		assert.Equal(t, []owsms.StatusUpdate{
+			{MTID: 1, Err: context.DeadlineExceeded, Final: true},
+		}, updates[1])
+	})
+}
@@ -0,0 +1,263 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package queue sends SMS asynchronously from a durable queue, retrying transient failures with backoff and
+// dead-lettering permanent ones, so that messages survive gateway outages and process restarts.
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+)
+
+const (
+	defaultWorkers      = 1
+	defaultMaxAttempts  = 5
+	defaultMinBackoff   = time.Second
+	defaultMaxBackoff   = 5 * time.Minute
+	defaultPollInterval = time.Second
+)
+
+// Sender sends SMS. *owsms.Client satisfies this interface.
+type Sender interface {
+	SendSMSWithContext(ctx context.Context, input *owsms.SendSMSInput) (*owsms.SendSMSOutput, *http.Response, error)
+}
+
+// Message queued message structure.
+type Message struct {
+	ID          string             `json:"id"`           // Unique ID of the message.
+	Input       owsms.SendSMSInput `json:"input"`        // Send SMS input of the message, narrowed to the recipients left to send to on retries.
+	Attempts    int                `json:"attempts"`     // Number of attempts made.
+	NextAttempt time.Time          `json:"next_attempt"` // Time the next attempt is due at.
+	LastError   string             `json:"last_error"`   // Error of the last attempt, if any.
+}
+
+func (m *Message) clone() *Message {
+	c := *m
+	c.Input.MobileNo = append([]string(nil), m.Input.MobileNo...)
+	return &c
+}
+
+// Queue outbound SMS queue structure. Messages are delivered at least once: a message is only removed from the
+// store once the gateway accepted it, dead-lettered once it failed permanently or MaxAttempts times.
+type Queue struct {
+	Sender       Sender        // Sender of the SMS, usually an *owsms.Client.
+	Store        Store         // Store of the messages.
+	Workers      int           // Number of messages sent concurrently by Run. Defaults to 1.
+	MaxAttempts  int           // Maximum number of attempts of a message before it is dead-lettered. Defaults to 5.
+	MinBackoff   time.Duration // Backoff before the first retry, doubled on every subsequent retry. Defaults to 1s.
+	MaxBackoff   time.Duration // Upper bound of the backoff between retries. Defaults to 5m.
+	PollInterval time.Duration // Delay before idle workers check for due messages again. Defaults to 1s.
+	Clock        owsms.Clock   // Clock used to schedule retries. Defaults to the system clock.
+}
+
+// Enqueue validates the input and stores it to be sent by the workers, returning the queued message.
+//
+// When the input has no IdempotencyKey, one derived from the message ID is set, so that a message sent again after
+// a crash is deduplicated by clients configured with an idempotency store.
+func (q *Queue) Enqueue(input *owsms.SendSMSInput) (*Message, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	id, err := newMessageID()
+	if err != nil {
+		return nil, err
+	}
+	msg := &Message{ID: id, Input: *input, NextAttempt: q.clock().Now()}
+	msg.Input.MobileNo = append([]string(nil), input.MobileNo...)
+	if msg.Input.IdempotencyKey == "" {
+		msg.Input.IdempotencyKey = "queue/" + id
+	}
+	if err := q.Store.Put(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Depth returns the number of messages waiting to be sent, including messages being sent.
+func (q *Queue) Depth() (int, error) {
+	return q.Store.Len()
+}
+
+// Run runs the workers until the context is done, returning the context's error, or until the store fails,
+// returning its error.
+func (q *Queue) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := q.Workers
+	if workers < 1 {
+		workers = defaultWorkers
+	}
+	pollInterval := q.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		storeErr error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				processed, err := q.ProcessNext(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						once.Do(func() { storeErr = err })
+					}
+					cancel()
+					return
+				}
+				if processed {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-q.clock().After(pollInterval):
+				}
+			}
+		}()
+	}
+	<-ctx.Done()
+	wg.Wait()
+
+	if storeErr != nil {
+		return storeErr
+	}
+	return ctx.Err()
+}
+
+// ProcessNext sends the next due message, if any, reporting whether a message was processed.
+// Recipients failing transiently are retried with backoff, and recipients failing permanently, see owerr.Permanent,
+// are dead-lettered. When the context is done during the attempt, the message is released without counting
+// the attempt and the context's error is returned.
+func (q *Queue) ProcessNext(ctx context.Context) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	msg, ok, err := q.Store.Claim(q.clock().Now())
+	if err != nil || !ok {
+		return false, err
+	}
+
+	output, _, err := q.Sender.SendSMSWithContext(ctx, &msg.Input)
+	if ctx.Err() != nil {
+		if err := q.Store.Put(msg); err != nil {
+			return false, err
+		}
+		return false, ctx.Err()
+	}
+
+	msg.Attempts++
+	// An output returned along with an error holds the error of each recipient, classified one by one below.
+	switch {
+	case output == nil && owerr.Permanent(err):
+		msg.LastError = err.Error()
+		return true, q.Store.DeadLetter(msg)
+	case output == nil:
+		return true, q.retry(msg, err)
+	}
+
+	var (
+		transient, permanent       []string
+		transientErr, permanentErr error
+	)
+	for _, result := range output.Results {
+		switch {
+		case result.Err == nil:
+		case owerr.Permanent(result.Err):
+			permanent = append(permanent, result.MobileNo)
+			permanentErr = result.Err
+		default:
+			transient = append(transient, result.MobileNo)
+			transientErr = result.Err
+		}
+	}
+
+	if len(permanent) > 0 {
+		id, err := newMessageID()
+		if err != nil {
+			return true, err
+		}
+		dead := msg.clone()
+		dead.ID = id
+		dead.Input.MobileNo = permanent
+		dead.LastError = permanentErr.Error()
+		if err := q.Store.DeadLetter(dead); err != nil {
+			return true, err
+		}
+	}
+	if len(transient) > 0 {
+		msg.Input.MobileNo = transient
+		// The recipients sent to changed, the original key would return the output of the previous attempt.
+		msg.Input.IdempotencyKey += "/" + strconv.Itoa(msg.Attempts)
+		return true, q.retry(msg, transientErr)
+	}
+	return true, q.Store.Ack(msg.ID)
+}
+
+// retry schedules the next attempt of the message failing with err, or dead-letters it after MaxAttempts.
+func (q *Queue) retry(msg *Message, err error) error {
+	msg.LastError = err.Error()
+
+	maxAttempts := q.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = defaultMaxAttempts
+	}
+	if msg.Attempts >= maxAttempts {
+		return q.Store.DeadLetter(msg)
+	}
+
+	msg.NextAttempt = q.clock().Now().Add(q.backoff(msg.Attempts))
+	return q.Store.Put(msg)
+}
+
+// backoff returns the delay before the given retry, starting from 1.
+func (q *Queue) backoff(retry int) time.Duration {
+	min, max := q.MinBackoff, q.MaxBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	d := min
+	for i := 1; i < retry && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func (q *Queue) clock() owsms.Clock {
+	if q.Clock == nil {
+		return owsms.SystemClock{}
+	}
+	return q.Clock
+}
+
+// newMessageID returns a random message ID.
+func newMessageID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
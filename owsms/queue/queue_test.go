@@ -0,0 +1,308 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock owsms.Clock only moving forward when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// fakeSender Sender replying with the outcomes provided in turn, then succeeding.
+type fakeSender struct {
+	mu       sync.Mutex
+	inputs   []owsms.SendSMSInput
+	outcomes []func(input *owsms.SendSMSInput) (*owsms.SendSMSOutput, error)
+}
+
+func (s *fakeSender) SendSMSWithContext(ctx context.Context, input *owsms.SendSMSInput) (*owsms.SendSMSOutput, *http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inputs = append(s.inputs, *input)
+	if len(s.outcomes) > 0 {
+		outcome := s.outcomes[0]
+		s.outcomes = s.outcomes[1:]
+		output, err := outcome(input)
+		return output, nil, err
+	}
+	return succeed(input)
+}
+
+func (s *fakeSender) sent() []owsms.SendSMSInput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]owsms.SendSMSInput(nil), s.inputs...)
+}
+
+func succeed(input *owsms.SendSMSInput) (*owsms.SendSMSOutput, *http.Response, error) {
+	output := &owsms.SendSMSOutput{}
+	for i, mobileNo := range input.MobileNo {
+		output.MTIDs = append(output.MTIDs, 145712468+i)
+		output.Results = append(output.Results, owsms.SendSMSResult{MobileNo: mobileNo, MTID: 145712468 + i})
+	}
+	return output, nil, nil
+}
+
+func fail(err error) func(input *owsms.SendSMSInput) (*owsms.SendSMSOutput, error) {
+	return func(input *owsms.SendSMSInput) (*owsms.SendSMSOutput, error) {
+		return nil, err
+	}
+}
+
+func TestQueue(t *testing.T) {
+	start := time.Date(2020, 6, 3, 0, 0, 0, 0, time.UTC)
+	outage := owerr.New(owerr.RequestFailure, "request failure", http.StatusServiceUnavailable)
+
+	t.Run("With transient failures", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{outcomes: []func(*owsms.SendSMSInput) (*owsms.SendSMSOutput, error){
+			fail(outage),
+			fail(errors.New("connection refused")),
+		}}
+		q := &queue.Queue{Sender: sender, Store: queue.NewMemoryStore(), Clock: clock}
+
+		msg, err := q.Enqueue(&owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "queue/"+msg.ID, msg.Input.IdempotencyKey)
+
+		depth, err := q.Depth()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, depth)
+
+		processed, err := q.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		// The retry is not due yet.
+		processed, err = q.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, processed)
+
+		clock.Advance(time.Second)
+		processed, err = q.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		clock.Advance(time.Second)
+		processed, err = q.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, processed)
+
+		clock.Advance(time.Second)
+		processed, err = q.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		depth, err = q.Depth()
+		assert.NoError(t, err)
+		assert.Zero(t, depth)
+		assert.Len(t, sender.sent(), 3)
+		for _, input := range sender.sent() {
+			assert.Equal(t, "queue/"+msg.ID, input.IdempotencyKey)
+		}
+	})
+
+	t.Run("With permanent failure", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		invalid := owerr.New(owerr.InvalidSenderID, "senderid parameter is invalid", http.StatusOK)
+		sender := &fakeSender{outcomes: []func(*owsms.SendSMSInput) (*owsms.SendSMSOutput, error){fail(invalid)}}
+		store := queue.NewMemoryStore()
+		q := &queue.Queue{Sender: sender, Store: store, Clock: clock}
+
+		msg, err := q.Enqueue(&owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}})
+		assert.NoError(t, err)
+
+		processed, err := q.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		depth, err := q.Depth()
+		assert.NoError(t, err)
+		assert.Zero(t, depth)
+
+		dead, err := store.DeadLetters()
+		assert.NoError(t, err)
+		assert.Len(t, dead, 1)
+		assert.Equal(t, msg.ID, dead[0].ID)
+		assert.Equal(t, 1, dead[0].Attempts)
+		assert.Equal(t, invalid.Error(), dead[0].LastError)
+	})
+
+	t.Run("With max attempts", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{outcomes: []func(*owsms.SendSMSInput) (*owsms.SendSMSOutput, error){
+			fail(outage), fail(outage), fail(outage),
+		}}
+		store := queue.NewMemoryStore()
+		q := &queue.Queue{Sender: sender, Store: store, Clock: clock, MaxAttempts: 3, MinBackoff: time.Minute}
+
+		_, err := q.Enqueue(&owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}})
+		assert.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			processed, err := q.ProcessNext(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, processed)
+			clock.Advance(time.Hour)
+		}
+
+		dead, err := store.DeadLetters()
+		assert.NoError(t, err)
+		assert.Len(t, dead, 1)
+		assert.Equal(t, 3, dead[0].Attempts)
+		assert.Equal(t, outage.Error(), dead[0].LastError)
+	})
+
+	t.Run("With per-recipient failures", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{outcomes: []func(*owsms.SendSMSInput) (*owsms.SendSMSOutput, error){
+			func(input *owsms.SendSMSInput) (*owsms.SendSMSOutput, error) {
+				return &owsms.SendSMSOutput{
+					MTIDs: []int{145712468},
+					Results: []owsms.SendSMSResult{
+						{MobileNo: "60123456789", MTID: 145712468},
+						{MobileNo: "6012", Err: owerr.New(owerr.InvalidMobileNo, "mobileno parameter is invalid", http.StatusOK)},
+						{MobileNo: "60129876543", Err: owerr.New(owerr.InsufficientCreditBalance, "insufficient credit balance", http.StatusOK)},
+					},
+				}, nil
+			},
+		}}
+		store := queue.NewMemoryStore()
+		q := &queue.Queue{Sender: sender, Store: store, Clock: clock}
+
+		msg, err := q.Enqueue(&owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789", "6012", "60129876543"}})
+		assert.NoError(t, err)
+
+		processed, err := q.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		dead, err := store.DeadLetters()
+		assert.NoError(t, err)
+		assert.Len(t, dead, 1)
+		assert.Equal(t, []string{"6012"}, dead[0].Input.MobileNo)
+		assert.Equal(t, "OneWaySMS: Error 200 (OK): mobileno parameter is invalid", dead[0].LastError)
+
+		depth, err := q.Depth()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, depth)
+
+		clock.Advance(time.Second)
+		processed, err = q.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		sent := sender.sent()
+		assert.Len(t, sent, 2)
+		assert.Equal(t, []string{"60129876543"}, sent[1].MobileNo)
+		assert.Equal(t, "queue/"+msg.ID+"/1", sent[1].IdempotencyKey)
+
+		depth, err = q.Depth()
+		assert.NoError(t, err)
+		assert.Zero(t, depth)
+	})
+
+	t.Run("With every recipient failed", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		invalid := owerr.New(owerr.InvalidMobileNo, "mobileno parameter is invalid", http.StatusOK)
+		sender := &fakeSender{outcomes: []func(*owsms.SendSMSInput) (*owsms.SendSMSOutput, error){
+			func(input *owsms.SendSMSInput) (*owsms.SendSMSOutput, error) {
+				return &owsms.SendSMSOutput{
+					MTIDs: []int{},
+					Results: []owsms.SendSMSResult{
+						{MobileNo: "6012", Err: invalid},
+						{MobileNo: "60129876543", Err: owerr.New(owerr.InsufficientCreditBalance, "insufficient credit balance", http.StatusOK)},
+					},
+				}, invalid
+			},
+		}}
+		store := queue.NewMemoryStore()
+		q := &queue.Queue{Sender: sender, Store: store, Clock: clock}
+
+		_, err := q.Enqueue(&owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"6012", "60129876543"}})
+		assert.NoError(t, err)
+
+		processed, err := q.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		// The recipients are classified one by one rather than by the error returned.
+		dead, err := store.DeadLetters()
+		assert.NoError(t, err)
+		assert.Len(t, dead, 1)
+		assert.Equal(t, []string{"6012"}, dead[0].Input.MobileNo)
+
+		depth, err := q.Depth()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, depth)
+	})
+
+	t.Run("With invalid input", func(t *testing.T) {
+		q := &queue.Queue{Sender: &fakeSender{}, Store: queue.NewMemoryStore()}
+
+		msg, err := q.Enqueue(&owsms.SendSMSInput{Message: "Hello World"})
+		_, ok := err.(owerr.ValidationError)
+		assert.True(t, ok)
+		assert.Nil(t, msg)
+	})
+}
+
+func TestQueueRun(t *testing.T) {
+	sender := &fakeSender{}
+	q := &queue.Queue{Sender: sender, Store: queue.NewMemoryStore(), Workers: 3, PollInterval: time.Millisecond}
+
+	for i := 0; i < 20; i++ {
+		_, err := q.Enqueue(&owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}})
+		assert.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- q.Run(ctx) }()
+
+	for {
+		depth, err := q.Depth()
+		assert.NoError(t, err)
+		if depth == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+	assert.Len(t, sender.sent(), 20)
+}
@@ -0,0 +1,308 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package queue
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/internal/fileutil"
+)
+
+// Store stores the messages of a queue. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put stores the pending message, replacing any message with the same ID and releasing its claim.
+	Put(msg *Message) error
+
+	// Claim returns the pending message with the earliest NextAttempt at or before now which is not claimed yet,
+	// claiming it until it is put, acknowledged or dead-lettered. It reports whether a message was found.
+	Claim(now time.Time) (*Message, bool, error)
+
+	// Ack removes the pending message with the ID once sent.
+	Ack(id string) error
+
+	// DeadLetter moves the message to the dead letters, removing any pending message with the same ID.
+	DeadLetter(msg *Message) error
+
+	// DeadLetters returns the dead-lettered messages, in the order they were dead-lettered.
+	DeadLetters() ([]*Message, error)
+
+	// Len returns the number of pending messages, including claimed ones.
+	Len() (int, error)
+}
+
+// messages pending and dead-lettered messages, shared by the store implementations.
+// Dead letters are kept in memory by MemoryStore only.
+type messages struct {
+	Pending map[string]*Message `json:"pending"`
+	Dead    []*Message          `json:"-"`
+
+	claimed map[string]bool
+}
+
+func newMessages() *messages {
+	return &messages{
+		Pending: make(map[string]*Message),
+		claimed: make(map[string]bool),
+	}
+}
+
+func (m *messages) put(msg *Message) {
+	m.Pending[msg.ID] = msg.clone()
+	delete(m.claimed, msg.ID)
+}
+
+func (m *messages) claim(now time.Time) (*Message, bool) {
+	var next *Message
+	for id, msg := range m.Pending {
+		if m.claimed[id] || msg.NextAttempt.After(now) {
+			continue
+		}
+		if next == nil || msg.NextAttempt.Before(next.NextAttempt) ||
+			(msg.NextAttempt.Equal(next.NextAttempt) && msg.ID < next.ID) {
+			next = msg
+		}
+	}
+	if next == nil {
+		return nil, false
+	}
+	m.claimed[next.ID] = true
+	return next.clone(), true
+}
+
+func (m *messages) ack(id string) {
+	delete(m.Pending, id)
+	delete(m.claimed, id)
+}
+
+func (m *messages) deadLetter(msg *Message) {
+	m.ack(msg.ID)
+	m.Dead = append(m.Dead, msg.clone())
+}
+
+func (m *messages) deadLetters() []*Message {
+	dead := make([]*Message, len(m.Dead))
+	for i, msg := range m.Dead {
+		dead[i] = msg.clone()
+	}
+	return dead
+}
+
+// MemoryStore in-memory message store. Messages are lost when the process exits.
+type MemoryStore struct {
+	mu       sync.Mutex
+	messages *messages
+}
+
+// NewMemoryStore initializes a new in-memory message store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		messages: newMessages(),
+	}
+}
+
+// Put stores the pending message.
+func (s *MemoryStore) Put(msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages.put(msg)
+	return nil
+}
+
+// Claim claims the next pending message due at now.
+func (s *MemoryStore) Claim(now time.Time) (*Message, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg, ok := s.messages.claim(now)
+	return msg, ok, nil
+}
+
+// Ack removes the pending message with the ID.
+func (s *MemoryStore) Ack(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages.ack(id)
+	return nil
+}
+
+// DeadLetter moves the message to the dead letters.
+func (s *MemoryStore) DeadLetter(msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages.deadLetter(msg)
+	return nil
+}
+
+// DeadLetters returns the dead-lettered messages.
+func (s *MemoryStore) DeadLetters() ([]*Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.messages.deadLetters(), nil
+}
+
+// Len returns the number of pending messages.
+func (s *MemoryStore) Len() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.messages.Pending), nil
+}
+
+// FileStore file-backed message store. Pending messages are persisted as JSON to the file on every change, allowing
+// them to survive process restarts, in which case messages claimed but not yet acknowledged are delivered again.
+// Dead letters are appended as JSON Lines to a separate file, at path with a .dead suffix, so that they do not
+// slow down changes to pending messages, and can be archived or removed while the store is not in use.
+// The files must not be shared between processes.
+type FileStore struct {
+	mu       sync.Mutex
+	path     string
+	deadPath string
+	messages *messages
+}
+
+// NewFileStore initializes a new file-backed message store, loading existing messages from path if it exists.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path:     path,
+		deadPath: path + ".dead",
+		messages: newMessages(),
+	}
+
+	// Terminate a dead letter partially written before a crash, so that the next one starts on its own line.
+	b, err := ioutil.ReadFile(s.deadPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		if err := s.appendDead([]byte("\n")); err != nil {
+			return nil, err
+		}
+	}
+
+	b, err = ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, s.messages); err != nil {
+			return nil, err
+		}
+		if s.messages.Pending == nil {
+			s.messages.Pending = make(map[string]*Message)
+		}
+	}
+	return s, nil
+}
+
+// Put stores the pending message and persists the pending messages to the store's file.
+func (s *FileStore) Put(msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages.put(msg)
+	return s.save()
+}
+
+// Claim claims the next pending message due at now. Claims are not persisted.
+func (s *FileStore) Claim(now time.Time) (*Message, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg, ok := s.messages.claim(now)
+	return msg, ok, nil
+}
+
+// Ack removes the pending message with the ID and persists the pending messages to the store's file.
+func (s *FileStore) Ack(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages.ack(id)
+	return s.save()
+}
+
+// DeadLetter appends the message to the dead letters file, then removes it from the pending messages.
+// A crash in between delivers the message again rather than losing it.
+func (s *FileStore) DeadLetter(msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := s.appendDead(append(b, '\n')); err != nil {
+		return err
+	}
+	s.messages.ack(msg.ID)
+	return s.save()
+}
+
+// DeadLetters reads the dead-lettered messages from the dead letters file.
+func (s *FileStore) DeadLetters() ([]*Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := ioutil.ReadFile(s.deadPath)
+	if os.IsNotExist(err) {
+		return []*Message{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dead := []*Message{}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		var msg Message
+		if len(line) <= 0 || json.Unmarshal(line, &msg) != nil {
+			// Empty line or dead letter partially written before a crash.
+			continue
+		}
+		dead = append(dead, &msg)
+	}
+	return dead, nil
+}
+
+// Len returns the number of pending messages.
+func (s *FileStore) Len() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.messages.Pending), nil
+}
+
+// save atomically replaces the store's file with the current pending messages.
+func (s *FileStore) save() error {
+	b, err := json.Marshal(s.messages)
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(s.path, b)
+}
+
+// appendDead appends the data to the dead letters file, creating it if needed.
+func (s *FileStore) appendDead(b []byte) error {
+	f, err := os.OpenFile(s.deadPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,121 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package queue_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2020, 6, 3, 0, 0, 0, 0, time.UTC)
+	store := queue.NewMemoryStore()
+
+	assert.NoError(t, store.Put(&queue.Message{ID: "b", NextAttempt: now}))
+	assert.NoError(t, store.Put(&queue.Message{ID: "a", NextAttempt: now.Add(-time.Minute)}))
+	assert.NoError(t, store.Put(&queue.Message{ID: "c", NextAttempt: now.Add(time.Minute)}))
+
+	msg, ok, err := store.Claim(now)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "a", msg.ID)
+
+	msg, ok, err = store.Claim(now)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "b", msg.ID)
+
+	// Claimed messages and messages not due are not claimed.
+	_, ok, err = store.Claim(now)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// Putting a claimed message releases it.
+	assert.NoError(t, store.Put(msg))
+	msg, ok, err = store.Claim(now)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "b", msg.ID)
+
+	assert.NoError(t, store.Ack("a"))
+	assert.NoError(t, store.DeadLetter(msg))
+	n, err := store.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	dead, err := store.DeadLetters()
+	assert.NoError(t, err)
+	assert.Len(t, dead, 1)
+	assert.Equal(t, "b", dead[0].ID)
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "queue")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "queue.json")
+
+	now := time.Date(2020, 6, 3, 0, 0, 0, 0, time.UTC)
+	input := owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}, IdempotencyKey: "queue/a"}
+
+	store, err := queue.NewFileStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put(&queue.Message{ID: "a", Input: input, NextAttempt: now}))
+	assert.NoError(t, store.Put(&queue.Message{ID: "b", Input: input, NextAttempt: now}))
+	assert.NoError(t, store.DeadLetter(&queue.Message{ID: "c", Input: input, Attempts: 5, LastError: "request failure"}))
+
+	// Claim a message, then crash before acknowledging it.
+	_, ok, err := store.Claim(now)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	store, err = queue.NewFileStore(path)
+	assert.NoError(t, err)
+	n, err := store.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	msg, ok, err := store.Claim(now)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, &queue.Message{ID: "a", Input: input, NextAttempt: now}, msg)
+
+	dead, err := store.DeadLetters()
+	assert.NoError(t, err)
+	assert.Equal(t, []*queue.Message{{ID: "c", Input: input, Attempts: 5, LastError: "request failure"}}, dead)
+
+	// Dead letters are kept out of the pending messages file.
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "request failure")
+
+	t.Run("With dead letter partially written", func(t *testing.T) {
+		path := filepath.Join(dir, "partial.json")
+		assert.NoError(t, ioutil.WriteFile(path+".dead", []byte(`{"ID":"a","Attem`), 0644))
+
+		store, err := queue.NewFileStore(path)
+		assert.NoError(t, err)
+		assert.NoError(t, store.DeadLetter(&queue.Message{ID: "b", Input: input, Attempts: 1}))
+
+		dead, err := store.DeadLetters()
+		assert.NoError(t, err)
+		assert.Equal(t, []*queue.Message{{ID: "b", Input: input, Attempts: 1}}, dead)
+	})
+
+	t.Run("With corrupted file", func(t *testing.T) {
+		corrupted := filepath.Join(dir, "corrupted.json")
+		assert.NoError(t, ioutil.WriteFile(corrupted, []byte("{"), 0644))
+
+		_, err := queue.NewFileStore(corrupted)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,134 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+)
+
+// Clock provides the current time and timers, allowing time to be faked in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock Clock backed by the time package.
+type SystemClock struct{}
+
+// Now returns the current time.
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// After waits for the duration to elapse and then sends the current time on the returned channel.
+func (SystemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// RateLimiter token bucket rate limiter, refilling rate tokens per second up to burst tokens.
+// A RateLimiter is safe for concurrent use and can be shared between clients to share a budget.
+type RateLimiter struct {
+	mu     sync.Mutex
+	clock  Clock
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter initializes a new rate limiter allowing rate requests per second on average,
+// with bursts of up to burst requests. The bucket starts full.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return NewRateLimiterWithClock(rate, burst, SystemClock{})
+}
+
+// NewRateLimiterWithClock initializes a new rate limiter with custom clock.
+func NewRateLimiterWithClock(rate float64, burst int, clock Clock) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		clock:  clock,
+		rate:   rate,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+// Allow reports whether a request can be made now, consuming a token if so.
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait blocks until a request can be made, consuming a token.
+// It fails fast with an owerr.RateLimited error, without waiting, when the context was marked with
+// WithRateLimitFailFast or when its deadline would be exceeded before a token is available.
+// Returns the context's error if it is done while waiting.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.refill()
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	var wait time.Duration
+	if l.rate > 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	deadline, hasDeadline := ctx.Deadline()
+	if l.rate <= 0 || isRateLimitFailFast(ctx) || (hasDeadline && deadline.Before(l.clock.Now().Add(wait))) {
+		l.tokens++
+		l.mu.Unlock()
+		return owerr.New(owerr.RateLimited, "rate limit exceeded", 0)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-l.clock.After(wait):
+		return nil
+	case <-ctx.Done():
+		// Give the reserved token back to the bucket.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// refill adds the tokens accumulated since the last refill. Must be called with the lock held.
+func (l *RateLimiter) refill() {
+	now := l.clock.Now()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.rate
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+}
+
+type rateLimitFailFastKey struct{}
+
+// WithRateLimitFailFast returns a copy of the context making rate limited requests fail immediately
+// with an owerr.RateLimited error instead of waiting for the rate limiter.
+func WithRateLimitFailFast(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rateLimitFailFastKey{}, true)
+}
+
+func isRateLimitFailFast(ctx context.Context) bool {
+	failFast, _ := ctx.Value(rateLimitFailFastKey{}).(bool)
+	return failFast
+}
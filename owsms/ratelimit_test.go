@@ -0,0 +1,216 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock Clock only moving forward when advanced.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 6, 3, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward, firing the timers due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if !w.at.After(c.now) {
+			w.ch <- c.now
+			continue
+		}
+		waiters = append(waiters, w)
+	}
+	c.waiters = waiters
+}
+
+// BlockUntil waits until n timers are pending.
+func (c *fakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		pending := len(c.waiters)
+		c.mu.Unlock()
+		if pending >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("With burst", func(t *testing.T) {
+		clock := newFakeClock()
+		limiter := owsms.NewRateLimiterWithClock(1, 3, clock)
+
+		assert.True(t, limiter.Allow())
+		assert.True(t, limiter.Allow())
+		assert.True(t, limiter.Allow())
+		assert.False(t, limiter.Allow())
+
+		clock.Advance(time.Second)
+		assert.True(t, limiter.Allow())
+		assert.False(t, limiter.Allow())
+
+		clock.Advance(time.Hour)
+		for i := 0; i < 3; i++ {
+			assert.True(t, limiter.Allow())
+		}
+		assert.False(t, limiter.Allow())
+	})
+
+	t.Run("With Wait blocking until a token is available", func(t *testing.T) {
+		clock := newFakeClock()
+		limiter := owsms.NewRateLimiterWithClock(2, 1, clock)
+		assert.NoError(t, limiter.Wait(context.Background()))
+
+		done := make(chan error)
+		go func() {
+			done <- limiter.Wait(context.Background())
+		}()
+		clock.BlockUntil(1)
+
+		select {
+		case <-done:
+			t.Fatal("Wait returned before a token was available")
+		default:
+		}
+
+		clock.Advance(500 * time.Millisecond)
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("With Wait failing fast", func(t *testing.T) {
+		clock := newFakeClock()
+		limiter := owsms.NewRateLimiterWithClock(1, 1, clock)
+		assert.NoError(t, limiter.Wait(context.Background()))
+
+		err := limiter.Wait(owsms.WithRateLimitFailFast(context.Background()))
+		owErr, ok := err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.RateLimited, owErr.Code())
+
+		// The failed request did not consume a token.
+		clock.Advance(time.Second)
+		assert.True(t, limiter.Allow())
+	})
+
+	t.Run("With Wait exceeding the context deadline", func(t *testing.T) {
+		clock := newFakeClock()
+		limiter := owsms.NewRateLimiterWithClock(0.1, 1, clock)
+		assert.NoError(t, limiter.Wait(context.Background()))
+
+		ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(time.Second))
+		defer cancel()
+
+		err := limiter.Wait(ctx)
+		owErr, ok := err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.RateLimited, owErr.Code())
+	})
+
+	t.Run("With Wait cancelled", func(t *testing.T) {
+		clock := newFakeClock()
+		limiter := owsms.NewRateLimiterWithClock(1, 1, clock)
+		assert.NoError(t, limiter.Wait(context.Background()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- limiter.Wait(ctx)
+		}()
+		clock.BlockUntil(1)
+		cancel()
+		assert.Equal(t, context.Canceled, <-done)
+
+		// The cancelled request gave its token back.
+		clock.Advance(time.Second)
+		assert.True(t, limiter.Allow())
+	})
+}
+
+func TestClientRateLimiters(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/api.aspx" {
+			fmt.Fprintln(w, "145712468")
+			return
+		}
+		fmt.Fprintln(w, "6500.5")
+	}))
+	defer ts.Close()
+
+	clock := newFakeClock()
+	svc := owsms.New(
+		owsms.WithBaseURL(ts.URL),
+		owsms.WithSendRateLimiter(owsms.NewRateLimiterWithClock(1, 1, clock)),
+		owsms.WithQueryRateLimiter(owsms.NewRateLimiterWithClock(1, 2, clock)),
+	)
+	ctx := owsms.WithRateLimitFailFast(context.Background())
+	input := &owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}}
+
+	_, _, err := svc.SendSMSWithContext(ctx, input)
+	assert.NoError(t, err)
+
+	_, _, err = svc.SendSMSWithContext(ctx, input)
+	owErr, ok := err.(owerr.Error)
+	assert.True(t, ok)
+	assert.Equal(t, owerr.RateLimited, owErr.Code())
+
+	// Credit balance lookups have their own budget.
+	_, _, err = svc.CheckCreditBalanceWithContext(ctx)
+	assert.NoError(t, err)
+	_, _, err = svc.CheckCreditBalanceWithContext(ctx)
+	assert.NoError(t, err)
+	_, _, err = svc.CheckCreditBalanceWithContext(ctx)
+	assert.Error(t, err)
+
+	clock.Advance(time.Second)
+	_, _, err = svc.SendSMSWithContext(ctx, input)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
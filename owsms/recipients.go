@@ -0,0 +1,88 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/phone"
+)
+
+// recipientPlan maps the recipients of a send SMS input to the numbers actually sent to the gateway.
+type recipientPlan struct {
+	results  []SendSMSResult // Result of each recipient, in the order of the input's MobileNo.
+	mobileNo []string        // Numbers to send to.
+	indexes  [][]int         // Indexes of the recipients of each number to send to.
+}
+
+// planRecipients plans the recipients of the input, normalizing and deduplicating numbers when enabled.
+// Recipients rejected before sending, being invalid or suppressed, have their result's Err set.
+func (c *Client) planRecipients(input *SendSMSInput) (*recipientPlan, error) {
+	plan := &recipientPlan{
+		results: make([]SendSMSResult, len(input.MobileNo)),
+	}
+	seen := make(map[string]int)
+	for i, mobileNo := range input.MobileNo {
+		plan.results[i].MobileNo = mobileNo
+
+		number := mobileNo
+		if c.normalizePhone {
+			n, err := phone.Normalize(mobileNo, c.phoneRegion)
+			if err != nil {
+				reason := err.Error()
+				var phoneErr *phone.Error
+				if errors.As(err, &phoneErr) {
+					reason = phoneErr.Reason
+				}
+				plan.results[i].Err = owerr.NewValidationError("SendSMSInput", "MobileNo", fmt.Sprintf("MobileNo %q is invalid: %s", mobileNo, reason))
+				continue
+			}
+			number = n
+		}
+
+		if c.suppressionList != nil {
+			suppressed, err := c.suppressionList.Suppressed(CanonicalMobileNo(number))
+			if err != nil {
+				return nil, err
+			}
+			if suppressed {
+				plan.results[i].Err = owerr.New(owerr.RecipientSuppressed, fmt.Sprintf("mobileno %s is suppressed", number), 0)
+				continue
+			}
+		}
+
+		if c.normalizePhone {
+			if j, ok := seen[number]; ok {
+				plan.indexes[j] = append(plan.indexes[j], i)
+				continue
+			}
+			seen[number] = len(plan.mobileNo)
+		}
+
+		plan.mobileNo = append(plan.mobileNo, number)
+		plan.indexes = append(plan.indexes, []int{i})
+	}
+	return plan, nil
+}
+
+// output merges the results of the numbers sent, in the order of the plan's mobileNo, into the send SMS output.
+func (p *recipientPlan) output(results []SendSMSResult) *SendSMSOutput {
+	output := &SendSMSOutput{
+		MTIDs:   make([]int, 0, len(results)),
+		Results: p.results,
+	}
+	for j, result := range results {
+		if result.Err == nil {
+			output.MTIDs = append(output.MTIDs, result.MTID)
+		}
+		for _, i := range p.indexes[j] {
+			output.Results[i].MTID = result.MTID
+			output.Results[i].Err = result.Err
+		}
+	}
+	return output
+}
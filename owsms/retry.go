@@ -0,0 +1,85 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+)
+
+// RetryPolicy retry policy structure. Retries are only performed when it is safe to do so:
+// credit balance and transaction status lookups are retried on connection errors and retryable statuses,
+// while send SMS requests are only retried when the connection could not be established, the request
+// being known not to be sent, so that a message is never sent twice.
+type RetryPolicy struct {
+	MaxAttempts     int                       // Maximum number of attempts, including the first one. Values below 2 disable retries.
+	MinBackoff      time.Duration             // Backoff before the first retry, doubled on every subsequent retry. Defaults to 100ms.
+	MaxBackoff      time.Duration             // Upper bound of the backoff between retries. Defaults to 5s.
+	RetryableStatus func(statusCode int) bool // Reports whether a response status is retryable. Defaults to DefaultRetryableStatus.
+}
+
+// DefaultRetryableStatus reports whether the status code denotes a transient gateway failure,
+// namely 429 Too Many Requests and any 5xx status.
+func DefaultRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry reports whether the attempt that produced resp and err can be safely retried.
+func (p RetryPolicy) shouldRetry(resp *http.Response, err error, idempotent bool) bool {
+	if err != nil {
+		return idempotent || dialError(err)
+	}
+	if !idempotent {
+		return false
+	}
+	retryable := p.RetryableStatus
+	if retryable == nil {
+		retryable = DefaultRetryableStatus
+	}
+	return retryable(resp.StatusCode)
+}
+
+// dialError reports whether the error occurred while dialing the connection, before anything was written to it.
+// Other errors, including those of custom Doers, may occur after the request was sent.
+func dialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// backoff returns the jittered delay before the given retry, starting from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	min, max := p.MinBackoff, p.MaxBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	d := min
+	for i := 1; i < retry && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	// Equal jitter, half of the delay is fixed and the other half is random.
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,234 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyDoer fails the first n requests before they are sent.
+type flakyDoer struct {
+	failures int32
+	calls    int32
+}
+
+func (d *flakyDoer) Do(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&d.calls, 1) <= d.failures {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// lossyDoer sends every request but fails to return its response.
+type lossyDoer struct {
+	calls int32
+}
+
+func (d *lossyDoer) Do(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&d.calls, 1)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return nil, errors.New("response lost")
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := owsms.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+
+	t.Run("CheckCreditBalance with transient gateway failure", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintln(w, "6500.5")
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithRetryPolicy(policy))
+
+		output, _, err := svc.CheckCreditBalance()
+		assert.NoError(t, err)
+		assert.Equal(t, float32(6500.5), output.CreditBalance)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("CheckTransactionStatus with exhausted attempts", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithRetryPolicy(policy))
+
+		output, _, err := svc.CheckTransactionStatus(&owsms.CheckTransactionStatusInput{MTID: 145712470})
+		assert.Error(t, err)
+		assert.Nil(t, output)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("CheckCreditBalance with non retryable status", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithRetryPolicy(policy))
+
+		_, _, err := svc.CheckCreditBalance()
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("CheckCreditBalance with custom retryable status", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		custom := policy
+		custom.RetryableStatus = func(statusCode int) bool { return false }
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithRetryPolicy(custom))
+
+		_, _, err := svc.CheckCreditBalance()
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("SendSMS with gateway failure is not retried", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithRetryPolicy(policy))
+
+		_, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789"},
+		})
+		assert.Error(t, err)
+		owErr, ok := err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.RequestFailure, owErr.Code())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("SendSMS with connection error before request was written", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			fmt.Fprintln(w, "145712468")
+		}))
+		defer ts.Close()
+
+		doer := &flakyDoer{failures: 2}
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithHTTPClient(doer), owsms.WithRetryPolicy(policy))
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{145712468}, output.MTIDs)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&doer.calls))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("SendSMS with custom Doer error after request was sent", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			fmt.Fprintln(w, "145712468")
+		}))
+		defer ts.Close()
+
+		doer := &lossyDoer{}
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithHTTPClient(doer), owsms.WithRetryPolicy(policy))
+
+		_, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789"},
+		})
+		assert.True(t, errors.Is(err, owerr.ErrRequestFailure))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&doer.calls))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("SendSMS with connection error after request was written", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithRetryPolicy(policy))
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789"},
+		})
+		assert.Error(t, err)
+		assert.Equal(t, err, output.Results[0].Err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("With context cancelled during backoff", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL), owsms.WithRetryPolicy(owsms.RetryPolicy{
+			MaxAttempts: 5,
+			MinBackoff:  time.Second,
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, _, err := svc.CheckCreditBalanceWithContext(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+func TestDefaultRetryableStatus(t *testing.T) {
+	assert.True(t, owsms.DefaultRetryableStatus(http.StatusTooManyRequests))
+	assert.True(t, owsms.DefaultRetryableStatus(http.StatusInternalServerError))
+	assert.True(t, owsms.DefaultRetryableStatus(http.StatusServiceUnavailable))
+	assert.False(t, owsms.DefaultRetryableStatus(http.StatusOK))
+	assert.False(t, owsms.DefaultRetryableStatus(http.StatusBadRequest))
+}
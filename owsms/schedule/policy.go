@@ -0,0 +1,100 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package schedule
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultZones time zones of the regions supported by the phone package, keyed by country calling code.
+// Zones are fixed offsets without daylight saving time. Countries spanning several zones use the zone of their
+// capital. Override entries with time.LoadLocation where precision matters.
+var DefaultZones = map[string]*time.Location{
+	"1":   time.FixedZone("UTC-5", -5*60*60),
+	"44":  time.UTC,
+	"60":  time.FixedZone("UTC+8", 8*60*60),
+	"61":  time.FixedZone("UTC+10", 10*60*60),
+	"62":  time.FixedZone("UTC+7", 7*60*60),
+	"63":  time.FixedZone("UTC+8", 8*60*60),
+	"64":  time.FixedZone("UTC+12", 12*60*60),
+	"65":  time.FixedZone("UTC+8", 8*60*60),
+	"66":  time.FixedZone("UTC+7", 7*60*60),
+	"81":  time.FixedZone("UTC+9", 9*60*60),
+	"82":  time.FixedZone("UTC+9", 9*60*60),
+	"84":  time.FixedZone("UTC+7", 7*60*60),
+	"86":  time.FixedZone("UTC+8", 8*60*60),
+	"91":  time.FixedZone("UTC+5:30", 5*60*60+30*60),
+	"673": time.FixedZone("UTC+8", 8*60*60),
+	"852": time.FixedZone("UTC+8", 8*60*60),
+	"853": time.FixedZone("UTC+8", 8*60*60),
+	"886": time.FixedZone("UTC+8", 8*60*60),
+}
+
+// QuietHours quiet hours policy structure, holding messages until the recipient's local quiet hours end.
+// Recipients are located by the country calling code prefix of their number, in the digits-with-country-code
+// form sent to the gateway, for example 60123456789.
+type QuietHours struct {
+	Start       time.Duration             // Local time of day quiet hours start at, as the duration since midnight, for example 21 * time.Hour.
+	End         time.Duration             // Local time of day quiet hours end at. Quiet hours wrap around midnight when End is before Start.
+	Zones       map[string]*time.Location // Time zones keyed by country calling code prefix, the longest matching prefix winning. Defaults to DefaultZones.
+	DefaultZone *time.Location            // Time zone of numbers matching no prefix. Defaults to UTC.
+}
+
+// Zone returns the time zone of the mobile number.
+func (q *QuietHours) Zone(mobileNo string) *time.Location {
+	zones := q.Zones
+	if zones == nil {
+		zones = DefaultZones
+	}
+	number := strings.TrimPrefix(mobileNo, "+")
+
+	var (
+		zone   *time.Location
+		prefix string
+	)
+	for p, z := range zones {
+		if len(p) > len(prefix) && strings.HasPrefix(number, p) {
+			zone, prefix = z, p
+		}
+	}
+	if zone == nil {
+		zone = q.DefaultZone
+	}
+	if zone == nil {
+		zone = time.UTC
+	}
+	return zone
+}
+
+// Quiet reports whether t falls within the quiet hours of the mobile number.
+func (q *QuietHours) Quiet(mobileNo string, t time.Time) bool {
+	return !q.NextAllowed(mobileNo, t).Equal(t)
+}
+
+// NextAllowed returns the earliest time at or after t outside the quiet hours of the mobile number.
+func (q *QuietHours) NextAllowed(mobileNo string, t time.Time) time.Time {
+	if q.Start == q.End {
+		return t
+	}
+
+	local := t.In(q.Zone(mobileNo))
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	offset := local.Sub(midnight)
+
+	switch {
+	case q.Start < q.End && offset >= q.Start && offset < q.End:
+		// Quiet hours within the day, for example 01:00 to 06:00.
+		return midnight.Add(q.End).In(t.Location())
+	case q.Start > q.End && offset >= q.Start:
+		// Quiet hours wrapping around midnight, before midnight.
+		return midnight.AddDate(0, 0, 1).Add(q.End).In(t.Location())
+	case q.Start > q.End && offset < q.End:
+		// Quiet hours wrapping around midnight, after midnight.
+		return midnight.Add(q.End).In(t.Location())
+	default:
+		return t
+	}
+}
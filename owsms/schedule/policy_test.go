@@ -0,0 +1,108 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuietHours(t *testing.T) {
+	overnight := &schedule.QuietHours{Start: 21 * time.Hour, End: 8 * time.Hour}
+	early := &schedule.QuietHours{Start: time.Hour, End: 6*time.Hour + 30*time.Minute}
+
+	tests := []struct {
+		desc     string
+		policy   *schedule.QuietHours
+		mobileNo string
+		at       time.Time
+		expected time.Time
+	}{
+		{
+			desc:     "With allowed local time",
+			policy:   overnight,
+			mobileNo: "60123456789",
+			at:       time.Date(2020, 6, 3, 4, 0, 0, 0, time.UTC), // 12:00 in Malaysia.
+			expected: time.Date(2020, 6, 3, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "With quiet hours before midnight",
+			policy:   overnight,
+			mobileNo: "60123456789",
+			at:       time.Date(2020, 6, 3, 14, 0, 0, 0, time.UTC), // 22:00 in Malaysia.
+			expected: time.Date(2020, 6, 4, 0, 0, 0, 0, time.UTC),  // 08:00 in Malaysia.
+		},
+		{
+			desc:     "With quiet hours after midnight",
+			policy:   overnight,
+			mobileNo: "60123456789",
+			at:       time.Date(2020, 6, 2, 19, 0, 0, 0, time.UTC), // 03:00 in Malaysia.
+			expected: time.Date(2020, 6, 3, 0, 0, 0, 0, time.UTC),  // 08:00 in Malaysia.
+		},
+		{
+			desc:     "With another time zone",
+			policy:   overnight,
+			mobileNo: "919876543210",
+			at:       time.Date(2020, 6, 3, 14, 0, 0, 0, time.UTC), // 19:30 in India.
+			expected: time.Date(2020, 6, 3, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "With longest matching prefix",
+			policy:   overnight,
+			mobileNo: "85291234567",
+			at:       time.Date(2020, 6, 3, 14, 0, 0, 0, time.UTC), // 22:00 in Hong Kong, 21:30 in India.
+			expected: time.Date(2020, 6, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "With unknown prefix",
+			policy:   overnight,
+			mobileNo: "99912345678",
+			at:       time.Date(2020, 6, 3, 14, 0, 0, 0, time.UTC),
+			expected: time.Date(2020, 6, 3, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "With quiet hours within the day",
+			policy:   early,
+			mobileNo: "6591234567",
+			at:       time.Date(2020, 6, 3, 18, 0, 0, 0, time.UTC),  // 02:00 in Singapore.
+			expected: time.Date(2020, 6, 3, 22, 30, 0, 0, time.UTC), // 06:30 in Singapore.
+		},
+		{
+			desc:     "With end of quiet hours",
+			policy:   early,
+			mobileNo: "6591234567",
+			at:       time.Date(2020, 6, 3, 22, 30, 0, 0, time.UTC),
+			expected: time.Date(2020, 6, 3, 22, 30, 0, 0, time.UTC),
+		},
+		{
+			desc:     "Without quiet hours",
+			policy:   &schedule.QuietHours{},
+			mobileNo: "60123456789",
+			at:       time.Date(2020, 6, 3, 19, 0, 0, 0, time.UTC),
+			expected: time.Date(2020, 6, 3, 19, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			actual := test.policy.NextAllowed(test.mobileNo, test.at)
+			assert.True(t, test.expected.Equal(actual), "expected %v, got %v", test.expected, actual)
+			assert.Equal(t, !test.expected.Equal(test.at), test.policy.Quiet(test.mobileNo, test.at))
+		})
+	}
+
+	t.Run("With custom zones", func(t *testing.T) {
+		policy := &schedule.QuietHours{
+			Start:       21 * time.Hour,
+			End:         8 * time.Hour,
+			Zones:       map[string]*time.Location{"60": time.UTC},
+			DefaultZone: time.FixedZone("UTC+8", 8*60*60),
+		}
+		assert.Equal(t, time.UTC, policy.Zone("60123456789"))
+		assert.Equal(t, "UTC+8", policy.Zone("6591234567").String())
+	})
+}
@@ -0,0 +1,270 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package schedule holds SMS until a scheduled time and outside the quiet hours of each recipient's time zone,
+// persisting pending jobs through a Store and sending them once due.
+package schedule
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+)
+
+const (
+	defaultInterval    = time.Minute
+	defaultRetryDelay  = time.Minute
+	defaultMaxAttempts = 5
+)
+
+// Sender sends SMS. *owsms.Client satisfies this interface.
+type Sender interface {
+	SendSMSWithContext(ctx context.Context, input *owsms.SendSMSInput) (*owsms.SendSMSOutput, *http.Response, error)
+}
+
+// Job scheduled send SMS job structure.
+type Job struct {
+	ID       string             `json:"id"`       // Unique ID of the job.
+	Input    owsms.SendSMSInput `json:"input"`    // Send SMS input of the job.
+	SendAt   time.Time          `json:"send_at"`  // Time the job is due at.
+	Attempts int                `json:"attempts"` // Number of attempts of the job which failed transiently.
+}
+
+func (j *Job) clone() *Job {
+	c := *j
+	c.Input.MobileNo = append([]string(nil), j.Input.MobileNo...)
+	return &c
+}
+
+// Result result structure of a job sent by RunDue.
+type Result struct {
+	Job    *Job                 // Job sent.
+	Output *owsms.SendSMSOutput // Output of the send SMS request, nil on error.
+	Err    error                // Error of the send SMS request.
+	Retry  *Job                 // Job saved to send the recipients which failed transiently again, nil if none.
+}
+
+// Scheduler job scheduler structure.
+type Scheduler struct {
+	Sender     Sender        // Sender of the SMS, usually an *owsms.Client.
+	Store      Store         // Store of the pending jobs.
+	QuietHours *QuietHours   // Optional quiet hours policy, holding recipients until their quiet hours end.
+	Clock      owsms.Clock   // Clock used to determine due jobs. Defaults to the system clock.
+	Interval   time.Duration // Delay between the rounds of Run. Defaults to 1m.
+
+	RetryDelay  time.Duration // Delay before recipients which failed transiently are sent again. Defaults to 1m.
+	MaxAttempts int           // Maximum number of attempts of a job failing transiently. Defaults to 5.
+	OnResult    func(Result)  // Optional function receiving the result of every job sent by Run.
+}
+
+// Schedule validates the input and schedules it to be sent at sendAt, or as soon as possible when sendAt is zero.
+// With quiet hours, recipients are held until their quiet hours end, the input being split into one job per
+// distinct send time. The jobs saved are returned.
+//
+// An IdempotencyKey of the input is suffixed with the ID of each job, so that the jobs are not deduplicated
+// against each other. When the input has no IdempotencyKey, one derived from the job ID is set, so that a job sent
+// again is deduplicated by clients configured with an idempotency store.
+func (s *Scheduler) Schedule(input *owsms.SendSMSInput, sendAt time.Time) ([]*Job, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if sendAt.IsZero() {
+		sendAt = s.now()
+	}
+
+	jobs, err := s.split(input, input.MobileNo, sendAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if err := s.Store.Save(job); err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// RunDue sends the jobs due, returning their results. Jobs are deleted from the store before being sent, so that a
+// crash never sends a job twice. Recipients failing transiently, with an error which is not owerr.Permanent or because
+// the context is done, are saved again under the same job to be sent after RetryDelay, until the job reaches
+// MaxAttempts. Other failures are not retried. As the gateway may have accepted a request which failed, for example
+// on a timeout, a retried recipient may be delivered twice. Recipients whose quiet hours started while the job was pending are
+// rescheduled to the end of their quiet hours instead of being sent.
+func (s *Scheduler) RunDue(ctx context.Context) ([]Result, error) {
+	now := s.now()
+	jobs, err := s.Store.Due(now)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		var mobileNo, held []string
+		for _, number := range job.Input.MobileNo {
+			if s.QuietHours != nil && s.QuietHours.Quiet(number, now) {
+				held = append(held, number)
+				continue
+			}
+			mobileNo = append(mobileNo, number)
+		}
+
+		if len(held) > 0 {
+			rescheduled, err := s.split(&job.Input, held, now)
+			if err != nil {
+				return results, err
+			}
+			for _, r := range rescheduled {
+				if err := s.Store.Save(r); err != nil {
+					return results, err
+				}
+			}
+		}
+		if err := s.Store.Delete(job.ID); err != nil {
+			return results, err
+		}
+		if len(mobileNo) <= 0 {
+			continue
+		}
+
+		input := job.Input
+		input.MobileNo = mobileNo
+		output, _, err := s.Sender.SendSMSWithContext(ctx, &input)
+		result := Result{Job: job, Output: output, Err: err}
+		if retry := s.retryJob(ctx, job, mobileNo, output, err, now); retry != nil {
+			if err := s.Store.Save(retry); err != nil {
+				return append(results, result), err
+			}
+			result.Retry = retry
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Run runs due jobs every interval until the context is done, returning the context's error.
+// The result of every job sent is passed to OnResult. Store errors are returned, failing send SMS requests are not.
+func (s *Scheduler) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	for {
+		results, err := s.RunDue(ctx)
+		if s.OnResult != nil {
+			for _, result := range results {
+				s.OnResult(result)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock().After(interval):
+		}
+	}
+}
+
+// retryJob returns the job sending the recipients of the job which failed transiently again,
+// nil if there are none or the job reached its maximum attempts.
+func (s *Scheduler) retryJob(ctx context.Context, job *Job, mobileNo []string, output *owsms.SendSMSOutput, err error, now time.Time) *Job {
+	var failed []string
+	switch {
+	case output != nil:
+		// An output returned along with an error holds the error of each recipient.
+		for _, result := range output.Results {
+			if result.Err != nil && (ctx.Err() != nil || !owerr.Permanent(result.Err)) {
+				failed = append(failed, result.MobileNo)
+			}
+		}
+	case err != nil && (ctx.Err() != nil || !owerr.Permanent(err)):
+		failed = mobileNo
+	}
+
+	maxAttempts := s.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	if len(failed) <= 0 || job.Attempts+1 >= maxAttempts {
+		return nil
+	}
+
+	delay := s.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	retry := job.clone()
+	retry.Input.MobileNo = failed
+	retry.SendAt = now.Add(delay)
+	retry.Attempts++
+	if len(failed) != len(mobileNo) {
+		// The recipients sent to changed, the original key would return the output of the previous attempt.
+		retry.Input.IdempotencyKey += "/" + strconv.Itoa(retry.Attempts)
+	}
+	return retry
+}
+
+// split splits the recipients of the input into jobs by the earliest time each can be sent at or after sendAt.
+func (s *Scheduler) split(input *owsms.SendSMSInput, mobileNo []string, sendAt time.Time) ([]*Job, error) {
+	var (
+		jobs  []*Job
+		index = make(map[int64]*Job)
+	)
+	for _, number := range mobileNo {
+		at := sendAt
+		if s.QuietHours != nil {
+			at = s.QuietHours.NextAllowed(number, sendAt)
+		}
+
+		job, ok := index[at.UnixNano()]
+		if !ok {
+			id, err := newJobID()
+			if err != nil {
+				return nil, err
+			}
+			job = &Job{ID: id, Input: *input, SendAt: at}
+			job.Input.MobileNo = nil
+			if input.IdempotencyKey != "" {
+				job.Input.IdempotencyKey = input.IdempotencyKey + "/" + id
+			} else {
+				job.Input.IdempotencyKey = "schedule/" + id
+			}
+			index[at.UnixNano()] = job
+			jobs = append(jobs, job)
+		}
+		job.Input.MobileNo = append(job.Input.MobileNo, number)
+	}
+	return jobs, nil
+}
+
+func (s *Scheduler) clock() owsms.Clock {
+	if s.Clock == nil {
+		return owsms.SystemClock{}
+	}
+	return s.Clock
+}
+
+func (s *Scheduler) now() time.Time {
+	return s.clock().Now()
+}
+
+// newJobID returns a random job ID.
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,336 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package schedule_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock owsms.Clock only moving forward when advanced.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, ch)
+	return ch
+}
+
+// Tick advances the clock and fires every pending timer.
+func (c *fakeClock) Tick(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, ch := range c.waiters {
+		ch <- c.now
+	}
+	c.waiters = nil
+}
+
+// BlockUntilWaiting waits until a timer is pending.
+func (c *fakeClock) BlockUntilWaiting() {
+	for {
+		c.mu.Lock()
+		pending := len(c.waiters)
+		c.mu.Unlock()
+		if pending > 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// fakeSender Sender recording the inputs sent, failing the recipients in failed with their error.
+type fakeSender struct {
+	mu     sync.Mutex
+	inputs []owsms.SendSMSInput
+	err    error
+	failed map[string]error
+}
+
+func (s *fakeSender) SendSMSWithContext(ctx context.Context, input *owsms.SendSMSInput) (*owsms.SendSMSOutput, *http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inputs = append(s.inputs, *input)
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+	output := &owsms.SendSMSOutput{}
+	for i, mobileNo := range input.MobileNo {
+		if err := s.failed[mobileNo]; err != nil {
+			output.Results = append(output.Results, owsms.SendSMSResult{MobileNo: mobileNo, Err: err})
+			continue
+		}
+		output.MTIDs = append(output.MTIDs, 145712468+i)
+		output.Results = append(output.Results, owsms.SendSMSResult{MobileNo: mobileNo, MTID: 145712468 + i})
+	}
+	return output, nil, nil
+}
+
+func (s *fakeSender) sent() []owsms.SendSMSInput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]owsms.SendSMSInput(nil), s.inputs...)
+}
+
+func TestScheduler(t *testing.T) {
+	// 12:00 in Malaysia, 09:30 in India.
+	start := time.Date(2020, 6, 3, 4, 0, 0, 0, time.UTC)
+	quietHours := &schedule.QuietHours{Start: 21 * time.Hour, End: 8 * time.Hour}
+
+	t.Run("With send time", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{}
+		store := schedule.NewMemoryStore()
+		s := &schedule.Scheduler{Sender: sender, Store: store, Clock: clock}
+
+		jobs, err := s.Schedule(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789", "919876543210"},
+		}, start.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 1)
+		assert.Equal(t, []string{"60123456789", "919876543210"}, jobs[0].Input.MobileNo)
+
+		results, err := s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+		assert.Empty(t, sender.sent())
+
+		clock.Tick(time.Hour)
+		results, err = s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, []int{145712468, 145712469}, results[0].Output.MTIDs)
+		assert.Equal(t, []owsms.SendSMSInput{{
+			Message:        "Hello World",
+			MobileNo:       []string{"60123456789", "919876543210"},
+			IdempotencyKey: "schedule/" + jobs[0].ID,
+		}}, sender.sent())
+
+		n, err := store.Len()
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("With quiet hours", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{}
+		store := schedule.NewMemoryStore()
+		s := &schedule.Scheduler{Sender: sender, Store: store, QuietHours: quietHours, Clock: clock}
+
+		// 22:00 in Malaysia, 19:30 in India.
+		jobs, err := s.Schedule(&owsms.SendSMSInput{
+			Message:        "Sale starts now",
+			MobileNo:       []string{"60123456789", "919876543210", "60129876543"},
+			IdempotencyKey: "sale",
+		}, start.Add(10*time.Hour))
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 2)
+		assert.Equal(t, []string{"60123456789", "60129876543"}, jobs[0].Input.MobileNo)
+		assert.True(t, time.Date(2020, 6, 4, 0, 0, 0, 0, time.UTC).Equal(jobs[0].SendAt))
+		assert.Equal(t, "sale/"+jobs[0].ID, jobs[0].Input.IdempotencyKey)
+		assert.Equal(t, []string{"919876543210"}, jobs[1].Input.MobileNo)
+		assert.True(t, start.Add(10*time.Hour).Equal(jobs[1].SendAt))
+		assert.Equal(t, "sale/"+jobs[1].ID, jobs[1].Input.IdempotencyKey)
+
+		clock.Tick(10 * time.Hour)
+		results, err := s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, jobs[1].ID, results[0].Job.ID)
+
+		clock.Tick(10 * time.Hour)
+		results, err = s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, jobs[0].ID, results[0].Job.ID)
+
+		sent := sender.sent()
+		assert.Len(t, sent, 2)
+		assert.Equal(t, []string{"919876543210"}, sent[0].MobileNo)
+		assert.Equal(t, []string{"60123456789", "60129876543"}, sent[1].MobileNo)
+	})
+
+	t.Run("With quiet hours started while pending", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{}
+		store := schedule.NewMemoryStore()
+		s := &schedule.Scheduler{Sender: sender, Store: store, QuietHours: quietHours, Clock: clock}
+
+		jobs, err := s.Schedule(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789", "919876543210"},
+		}, time.Time{})
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 1)
+
+		// The scheduler was down until 22:00 in Malaysia, 19:30 in India.
+		clock.Tick(10 * time.Hour)
+		results, err := s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, []owsms.SendSMSInput{{
+			Message:        "Hello World",
+			MobileNo:       []string{"919876543210"},
+			IdempotencyKey: "schedule/" + jobs[0].ID,
+		}}, sender.sent())
+
+		n, err := store.Len()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		pending, err := store.Due(time.Date(2020, 6, 4, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, []string{"60123456789"}, pending[0].Input.MobileNo)
+		assert.Equal(t, "schedule/"+jobs[0].ID+"/"+pending[0].ID, pending[0].Input.IdempotencyKey)
+	})
+
+	t.Run("With failed send", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{err: owerr.New(owerr.InvalidSenderID, "senderid parameter is invalid", http.StatusOK)}
+		store := schedule.NewMemoryStore()
+		s := &schedule.Scheduler{Sender: sender, Store: store, Clock: clock}
+
+		_, err := s.Schedule(&owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}}, time.Time{})
+		assert.NoError(t, err)
+
+		results, err := s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, sender.err, results[0].Err)
+		assert.Nil(t, results[0].Output)
+		assert.Nil(t, results[0].Retry)
+
+		n, err := store.Len()
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("With transiently failed send", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{err: owerr.New(owerr.RequestFailure, "request failure", http.StatusBadGateway)}
+		store := schedule.NewMemoryStore()
+		s := &schedule.Scheduler{Sender: sender, Store: store, Clock: clock, RetryDelay: time.Minute, MaxAttempts: 2}
+
+		jobs, err := s.Schedule(&owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}}, time.Time{})
+		assert.NoError(t, err)
+
+		results, err := s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, sender.err, results[0].Err)
+		assert.Equal(t, jobs[0].ID, results[0].Retry.ID)
+		assert.Equal(t, jobs[0].Input.IdempotencyKey, results[0].Retry.Input.IdempotencyKey)
+		assert.Equal(t, 1, results[0].Retry.Attempts)
+		assert.True(t, start.Add(time.Minute).Equal(results[0].Retry.SendAt))
+
+		n, err := store.Len()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		// The last attempt is not retried.
+		clock.Tick(time.Minute)
+		results, err = s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, sender.err, results[0].Err)
+		assert.Nil(t, results[0].Retry)
+		assert.Len(t, sender.sent(), 2)
+
+		n, err = store.Len()
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("With transiently failed recipient", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{failed: map[string]error{
+			"60123456789": owerr.New(owerr.RequestFailure, "request failure", http.StatusBadGateway),
+		}}
+		store := schedule.NewMemoryStore()
+		s := &schedule.Scheduler{Sender: sender, Store: store, Clock: clock}
+
+		jobs, err := s.Schedule(&owsms.SendSMSInput{
+			Message:        "Hello World",
+			MobileNo:       []string{"60123456789", "60129876543"},
+			IdempotencyKey: "sale",
+		}, time.Time{})
+		assert.NoError(t, err)
+
+		results, err := s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, []string{"60123456789"}, results[0].Retry.Input.MobileNo)
+		// The recipients changed, the key of the first attempt would return its output.
+		assert.Equal(t, "sale/"+jobs[0].ID+"/1", results[0].Retry.Input.IdempotencyKey)
+	})
+
+	t.Run("With invalid input", func(t *testing.T) {
+		s := &schedule.Scheduler{Sender: &fakeSender{}, Store: schedule.NewMemoryStore()}
+
+		jobs, err := s.Schedule(&owsms.SendSMSInput{Message: "Hello World"}, time.Time{})
+		_, ok := err.(owerr.ValidationError)
+		assert.True(t, ok)
+		assert.Nil(t, jobs)
+	})
+}
+
+func TestSchedulerRun(t *testing.T) {
+	start := time.Date(2020, 6, 3, 4, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	sender := &fakeSender{}
+	results := make(chan schedule.Result, 1)
+	s := &schedule.Scheduler{
+		Sender:   sender,
+		Store:    schedule.NewMemoryStore(),
+		Clock:    clock,
+		Interval: time.Minute,
+		OnResult: func(result schedule.Result) { results <- result },
+	}
+
+	_, err := s.Schedule(&owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}}, start.Add(90*time.Second))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	clock.BlockUntilWaiting()
+	assert.Empty(t, sender.sent())
+	clock.Tick(time.Minute)
+	clock.BlockUntilWaiting()
+	assert.Empty(t, sender.sent())
+	clock.Tick(time.Minute)
+	clock.BlockUntilWaiting()
+	assert.Len(t, sender.sent(), 1)
+	assert.Equal(t, []int{145712468}, (<-results).Output.MTIDs)
+
+	cancel()
+	assert.True(t, errors.Is(<-done, context.Canceled))
+}
@@ -0,0 +1,189 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package schedule
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/internal/fileutil"
+)
+
+// Store stores the pending jobs of a scheduler. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save stores the job, replacing any job with the same ID.
+	Save(job *Job) error
+
+	// Due returns the jobs whose SendAt is at or before now, in the order of their SendAt.
+	Due(now time.Time) ([]*Job, error)
+
+	// Delete removes the job with the ID, if any.
+	Delete(id string) error
+
+	// Len returns the number of pending jobs.
+	Len() (int, error)
+}
+
+// jobs pending jobs, shared by the store implementations.
+type jobs struct {
+	Pending map[string]*Job `json:"pending"`
+}
+
+func newJobs() *jobs {
+	return &jobs{
+		Pending: make(map[string]*Job),
+	}
+}
+
+func (j *jobs) save(job *Job) {
+	j.Pending[job.ID] = job.clone()
+}
+
+func (j *jobs) due(now time.Time) []*Job {
+	var due []*Job
+	for _, job := range j.Pending {
+		if !job.SendAt.After(now) {
+			due = append(due, job.clone())
+		}
+	}
+	sort.Slice(due, func(a, b int) bool {
+		if due[a].SendAt.Equal(due[b].SendAt) {
+			return due[a].ID < due[b].ID
+		}
+		return due[a].SendAt.Before(due[b].SendAt)
+	})
+	return due
+}
+
+func (j *jobs) delete(id string) {
+	delete(j.Pending, id)
+}
+
+// MemoryStore in-memory job store. Jobs are lost when the process exits.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs *jobs
+}
+
+// NewMemoryStore initializes a new in-memory job store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		jobs: newJobs(),
+	}
+}
+
+// Save stores the job, replacing any job with the same ID.
+func (s *MemoryStore) Save(job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs.save(job)
+	return nil
+}
+
+// Due returns the jobs whose SendAt is at or before now, in the order of their SendAt.
+func (s *MemoryStore) Due(now time.Time) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.jobs.due(now), nil
+}
+
+// Delete removes the job with the ID, if any.
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs.delete(id)
+	return nil
+}
+
+// Len returns the number of pending jobs.
+func (s *MemoryStore) Len() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.jobs.Pending), nil
+}
+
+// FileStore file-backed job store. Pending jobs are persisted as JSON to the file on every change, allowing them to
+// survive process restarts. The file must not be shared between processes.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	jobs *jobs
+}
+
+// NewFileStore initializes a new file-backed job store, loading existing jobs from path if it exists.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path: path,
+		jobs: newJobs(),
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, s.jobs); err != nil {
+			return nil, err
+		}
+		if s.jobs.Pending == nil {
+			s.jobs.Pending = make(map[string]*Job)
+		}
+	}
+	return s, nil
+}
+
+// Save stores the job and persists the pending jobs to the store's file.
+func (s *FileStore) Save(job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs.save(job)
+	return s.save()
+}
+
+// Due returns the jobs whose SendAt is at or before now, in the order of their SendAt.
+func (s *FileStore) Due(now time.Time) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.jobs.due(now), nil
+}
+
+// Delete removes the job with the ID, if any, and persists the pending jobs to the store's file.
+func (s *FileStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs.delete(id)
+	return s.save()
+}
+
+// Len returns the number of pending jobs.
+func (s *FileStore) Len() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.jobs.Pending), nil
+}
+
+// save atomically replaces the store's file with the current pending jobs.
+func (s *FileStore) save() error {
+	b, err := json.Marshal(s.jobs)
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(s.path, b)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package schedule_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schedule")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "schedule.json")
+
+	now := time.Date(2020, 6, 3, 0, 0, 0, 0, time.UTC)
+	input := owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}, IdempotencyKey: "schedule/a"}
+
+	store, err := schedule.NewFileStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(&schedule.Job{ID: "b", Input: input, SendAt: now}))
+	assert.NoError(t, store.Save(&schedule.Job{ID: "a", Input: input, SendAt: now.Add(-time.Minute), Attempts: 1}))
+	assert.NoError(t, store.Save(&schedule.Job{ID: "c", Input: input, SendAt: now.Add(time.Minute)}))
+	assert.NoError(t, store.Delete("b"))
+
+	// Restart the process.
+	store, err = schedule.NewFileStore(path)
+	assert.NoError(t, err)
+	n, err := store.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	jobs, err := store.Due(now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []*schedule.Job{
+		{ID: "a", Input: input, SendAt: now.Add(-time.Minute), Attempts: 1},
+		{ID: "c", Input: input, SendAt: now.Add(time.Minute)},
+	}, jobs)
+
+	t.Run("With scheduler restarted", func(t *testing.T) {
+		path := filepath.Join(dir, "restart.json")
+		clock := &fakeClock{now: now}
+
+		store, err := schedule.NewFileStore(path)
+		assert.NoError(t, err)
+		s := &schedule.Scheduler{Sender: &fakeSender{}, Store: store, Clock: clock}
+		jobs, err := s.Schedule(&owsms.SendSMSInput{Message: "Hello World", MobileNo: []string{"60123456789"}}, now.Add(time.Hour))
+		assert.NoError(t, err)
+
+		store, err = schedule.NewFileStore(path)
+		assert.NoError(t, err)
+		sender := &fakeSender{}
+		s = &schedule.Scheduler{Sender: sender, Store: store, Clock: clock}
+
+		clock.Tick(time.Hour)
+		results, err := s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, []owsms.SendSMSInput{jobs[0].Input}, sender.sent())
+
+		n, err := store.Len()
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("With corrupted file", func(t *testing.T) {
+		corrupted := filepath.Join(dir, "corrupted.json")
+		assert.NoError(t, ioutil.WriteFile(corrupted, []byte("{"), 0644))
+
+		_, err := schedule.NewFileStore(corrupted)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,206 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/junwen-k/onewaysms-sdk-go/internal/fileutil"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/phone"
+)
+
+// OptOutKeywords keywords opting a mobile number out of further messages when replied as the first word
+// of an inbound message, matched case-insensitively.
+var OptOutKeywords = []string{"STOP", "STOPALL", "UNSUBSCRIBE", "CANCEL", "END", "QUIT"}
+
+// SuppressionList list of mobile numbers which must not be sent to, such as numbers which opted out.
+// Numbers are in the digits-with-country-code form sent to the gateway, for example 60123456789.
+// The client and SuppressOptOut canonicalize numbers, see CanonicalMobileNo, before calling the list.
+// Implementations must be safe for concurrent use.
+type SuppressionList interface {
+	// Suppressed reports whether the mobile number is suppressed.
+	Suppressed(mobileNo string) (bool, error)
+
+	// Add suppresses the mobile number.
+	Add(mobileNo string) error
+
+	// Remove stops suppressing the mobile number, such as after it opted in again.
+	Remove(mobileNo string) error
+}
+
+// MemorySuppressionList in-memory suppression list. Entries are lost when the process exits.
+type MemorySuppressionList struct {
+	mu      sync.RWMutex
+	numbers map[string]bool
+}
+
+// NewMemorySuppressionList initializes a new in-memory suppression list holding the mobile numbers provided.
+func NewMemorySuppressionList(mobileNo ...string) *MemorySuppressionList {
+	l := &MemorySuppressionList{
+		numbers: make(map[string]bool, len(mobileNo)),
+	}
+	for _, number := range mobileNo {
+		l.numbers[CanonicalMobileNo(number)] = true
+	}
+	return l
+}
+
+// Suppressed reports whether the mobile number is suppressed.
+func (l *MemorySuppressionList) Suppressed(mobileNo string) (bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.numbers[CanonicalMobileNo(mobileNo)], nil
+}
+
+// Add suppresses the mobile number.
+func (l *MemorySuppressionList) Add(mobileNo string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.numbers[CanonicalMobileNo(mobileNo)] = true
+	return nil
+}
+
+// Remove stops suppressing the mobile number.
+func (l *MemorySuppressionList) Remove(mobileNo string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.numbers, CanonicalMobileNo(mobileNo))
+	return nil
+}
+
+// FileSuppressionList file-backed suppression list. Numbers are persisted as a JSON array to the file,
+// allowing them to survive process restarts. The file must not be shared between processes.
+type FileSuppressionList struct {
+	mu      sync.RWMutex
+	path    string
+	numbers map[string]bool
+}
+
+// NewFileSuppressionList initializes a new file-backed suppression list, loading existing numbers from path if it exists.
+func NewFileSuppressionList(path string) (*FileSuppressionList, error) {
+	l := &FileSuppressionList{
+		path:    path,
+		numbers: make(map[string]bool),
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0 {
+		var numbers []string
+		if err := json.Unmarshal(b, &numbers); err != nil {
+			return nil, err
+		}
+		for _, number := range numbers {
+			l.numbers[CanonicalMobileNo(number)] = true
+		}
+	}
+	return l, nil
+}
+
+// Suppressed reports whether the mobile number is suppressed.
+func (l *FileSuppressionList) Suppressed(mobileNo string) (bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.numbers[CanonicalMobileNo(mobileNo)], nil
+}
+
+// Add suppresses the mobile number and persists the list to its file.
+func (l *FileSuppressionList) Add(mobileNo string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	mobileNo = CanonicalMobileNo(mobileNo)
+	if l.numbers[mobileNo] {
+		return nil
+	}
+	l.numbers[mobileNo] = true
+	return l.save()
+}
+
+// Remove stops suppressing the mobile number and persists the list to its file.
+func (l *FileSuppressionList) Remove(mobileNo string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	mobileNo = CanonicalMobileNo(mobileNo)
+	if !l.numbers[mobileNo] {
+		return nil
+	}
+	delete(l.numbers, mobileNo)
+	return l.save()
+}
+
+// save atomically replaces the list's file with the current numbers, sorted.
+func (l *FileSuppressionList) save() error {
+	numbers := make([]string, 0, len(l.numbers))
+	for number := range l.numbers {
+		numbers = append(numbers, number)
+	}
+	sort.Strings(numbers)
+
+	b, err := json.Marshal(numbers)
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(l.path, b)
+}
+
+// CanonicalMobileNo returns the mobile number in the canonical form suppression lists are matched by,
+// so that +60 12-345 6789, 0060123456789 and 60123456789 are the same number. Numbers in international
+// format are normalized, see phone.Normalize. Other numbers have the spaces, dashes, dots, parentheses
+// and leading + removed, as their region is unknown.
+func CanonicalMobileNo(mobileNo string) string {
+	if n, err := phone.Normalize(mobileNo, ""); err == nil {
+		return n
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '+', ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(mobileNo))
+}
+
+// IsOptOut reports whether the inbound message opts its sender out, its first word being one of OptOutKeywords.
+func IsOptOut(message *InboundMessage) bool {
+	words := strings.Fields(message.Message)
+	if len(words) <= 0 {
+		return false
+	}
+	word := strings.TrimRight(words[0], ".!")
+	for _, keyword := range OptOutKeywords {
+		if strings.EqualFold(word, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// SuppressOptOut adds the sender of the inbound message to the suppression list when the message opts out,
+// see IsOptOut, reporting whether the sender was added.
+func SuppressOptOut(list SuppressionList, message *InboundMessage) (bool, error) {
+	if !IsOptOut(message) {
+		return false, nil
+	}
+	if err := list.Add(CanonicalMobileNo(message.MobileNo)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
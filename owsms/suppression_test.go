@@ -0,0 +1,300 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingSuppressionList struct {
+	owsms.SuppressionList
+}
+
+func (l failingSuppressionList) Suppressed(mobileNo string) (bool, error) {
+	return false, errors.New("suppression list unavailable")
+}
+
+type recordingSuppressionList struct {
+	owsms.SuppressionList
+	added []string
+}
+
+func (l *recordingSuppressionList) Add(mobileNo string) error {
+	l.added = append(l.added, mobileNo)
+	return nil
+}
+
+func TestCanonicalMobileNo(t *testing.T) {
+	tests := []struct {
+		mobileNo string
+		expected string
+	}{
+		{mobileNo: "60123456789", expected: "60123456789"},
+		{mobileNo: "+60123456789", expected: "60123456789"},
+		{mobileNo: "+60 12-345 6789", expected: "60123456789"},
+		{mobileNo: "0060123456789", expected: "60123456789"},
+		{mobileNo: "6012-345 6789", expected: "60123456789"},
+		{mobileNo: " (6012) 345.6789 ", expected: "60123456789"},
+		{mobileNo: "012-3456789", expected: "0123456789"},
+	}
+	for _, test := range tests {
+		t.Run(test.mobileNo, func(t *testing.T) {
+			assert.Equal(t, test.expected, owsms.CanonicalMobileNo(test.mobileNo))
+		})
+	}
+}
+
+func TestMemorySuppressionList(t *testing.T) {
+	list := owsms.NewMemorySuppressionList("60123456789")
+
+	suppressed, err := list.Suppressed("60123456789")
+	assert.NoError(t, err)
+	assert.True(t, suppressed)
+
+	assert.NoError(t, list.Add("60129876543"))
+	suppressed, err = list.Suppressed("60129876543")
+	assert.NoError(t, err)
+	assert.True(t, suppressed)
+
+	assert.NoError(t, list.Remove("60123456789"))
+	suppressed, err = list.Suppressed("60123456789")
+	assert.NoError(t, err)
+	assert.False(t, suppressed)
+
+	t.Run("With different formats", func(t *testing.T) {
+		list := owsms.NewMemorySuppressionList("+60 12-345 6789")
+
+		for _, mobileNo := range []string{"60123456789", "+60123456789", "0060123456789", "6012-345 6789"} {
+			suppressed, err := list.Suppressed(mobileNo)
+			assert.NoError(t, err)
+			assert.True(t, suppressed, mobileNo)
+		}
+
+		assert.NoError(t, list.Remove("+60123456789"))
+		suppressed, err := list.Suppressed("60123456789")
+		assert.NoError(t, err)
+		assert.False(t, suppressed)
+	})
+}
+
+func TestFileSuppressionList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "owsms")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "suppression.json")
+
+	list, err := owsms.NewFileSuppressionList(path)
+	assert.NoError(t, err)
+	assert.NoError(t, list.Add("60129876543"))
+	assert.NoError(t, list.Add("60123456789"))
+	assert.NoError(t, list.Add("+60 12-345 6789"))
+	assert.NoError(t, list.Add("6598765432"))
+	assert.NoError(t, list.Remove("+65 9876 5432"))
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `["60123456789","60129876543"]`, string(b))
+
+	// Numbers survive a restart.
+	list, err = owsms.NewFileSuppressionList(path)
+	assert.NoError(t, err)
+	suppressed, err := list.Suppressed("60129876543")
+	assert.NoError(t, err)
+	assert.True(t, suppressed)
+	suppressed, err = list.Suppressed("6598765432")
+	assert.NoError(t, err)
+	assert.False(t, suppressed)
+
+	t.Run("With corrupted file", func(t *testing.T) {
+		corrupted := filepath.Join(dir, "corrupted.json")
+		assert.NoError(t, ioutil.WriteFile(corrupted, []byte("{"), 0644))
+
+		_, err := owsms.NewFileSuppressionList(corrupted)
+		assert.Error(t, err)
+	})
+}
+
+func TestIsOptOut(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected bool
+	}{
+		{message: "STOP", expected: true},
+		{message: "  stop please", expected: true},
+		{message: "Unsubscribe.", expected: true},
+		{message: "STOPALL", expected: true},
+		{message: "Don't stop", expected: false},
+		{message: "STOPPED", expected: false},
+		{message: "", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.message, func(t *testing.T) {
+			assert.Equal(t, test.expected, owsms.IsOptOut(&owsms.InboundMessage{MobileNo: "60123456789", Message: test.message}))
+		})
+	}
+}
+
+func TestSuppressOptOut(t *testing.T) {
+	list := owsms.NewMemorySuppressionList()
+
+	added, err := owsms.SuppressOptOut(list, &owsms.InboundMessage{MobileNo: "60123456789", Message: "Yes"})
+	assert.NoError(t, err)
+	assert.False(t, added)
+
+	added, err = owsms.SuppressOptOut(list, &owsms.InboundMessage{MobileNo: "60123456789", Message: "STOP"})
+	assert.NoError(t, err)
+	assert.True(t, added)
+
+	suppressed, err := list.Suppressed("60123456789")
+	assert.NoError(t, err)
+	assert.True(t, suppressed)
+
+	t.Run("With international format", func(t *testing.T) {
+		list := &recordingSuppressionList{}
+
+		added, err := owsms.SuppressOptOut(list, &owsms.InboundMessage{MobileNo: "+60 12-987 6543", Message: "STOP"})
+		assert.NoError(t, err)
+		assert.True(t, added)
+		assert.Equal(t, []string{"60129876543"}, list.added)
+	})
+}
+
+func TestWithSuppressionList(t *testing.T) {
+	t.Run("With suppressed recipients", func(t *testing.T) {
+		var mobileNo string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mobileNo = r.URL.Query().Get("mobileno")
+			fmt.Fprintln(w, "145712468")
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithPhoneNormalization("MY"),
+			owsms.WithSuppressionList(owsms.NewMemorySuppressionList("60129876543")),
+		)
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"012-9876543", "0123456789"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "60123456789", mobileNo)
+		assert.Equal(t, []int{145712468}, output.MTIDs)
+		assert.Equal(t, owsms.SendSMSResult{MobileNo: "0123456789", MTID: 145712468}, output.Results[1])
+		assert.Equal(t, "012-9876543", output.Results[0].MobileNo)
+		assert.Zero(t, output.Results[0].MTID)
+		owErr, ok := output.Results[0].Err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.RecipientSuppressed, owErr.Code())
+		assert.Equal(t, "mobileno 60129876543 is suppressed", owErr.Message())
+	})
+
+	t.Run("With differently formatted recipients", func(t *testing.T) {
+		var mobileNo string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mobileNo = r.URL.Query().Get("mobileno")
+			fmt.Fprintln(w, "145712468")
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithSuppressionList(owsms.NewMemorySuppressionList("60123456789")),
+		)
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"+60123456789", "6012-345 6789", "60129876543"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "60129876543", mobileNo)
+		assert.Equal(t, []int{145712468}, output.MTIDs)
+		assert.True(t, errors.Is(output.Results[0].Err, owerr.ErrRecipientSuppressed))
+		assert.True(t, errors.Is(output.Results[1].Err, owerr.ErrRecipientSuppressed))
+		assert.NoError(t, output.Results[2].Err)
+	})
+
+	t.Run("With every recipient suppressed", func(t *testing.T) {
+		var called bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithSuppressionList(owsms.NewMemorySuppressionList("60123456789")),
+		)
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789"},
+		})
+		owErr, ok := err.(owerr.Error)
+		assert.True(t, ok)
+		assert.Equal(t, owerr.RecipientSuppressed, owErr.Code())
+		assert.False(t, called)
+		assert.Empty(t, output.MTIDs)
+		assert.Equal(t, []owsms.SendSMSResult{{MobileNo: "60123456789", Err: err}}, output.Results)
+	})
+
+	t.Run("With every recipient suppressed or invalid", func(t *testing.T) {
+		var called bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithPhoneNormalization("MY"),
+			owsms.WithSuppressionList(owsms.NewMemorySuppressionList("60123456789")),
+		)
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"0123456789", "invalid"},
+		})
+		assert.True(t, errors.Is(err, owerr.ErrRecipientSuppressed))
+		assert.False(t, called)
+		assert.Len(t, output.Results, 2)
+		assert.True(t, errors.Is(output.Results[0].Err, owerr.ErrRecipientSuppressed))
+		_, ok := output.Results[1].Err.(owerr.ValidationError)
+		assert.True(t, ok)
+	})
+
+	t.Run("With suppression list error", func(t *testing.T) {
+		var called bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithSuppressionList(failingSuppressionList{}),
+		)
+
+		output, _, err := svc.SendSMS(&owsms.SendSMSInput{
+			Message:  "Hello World",
+			MobileNo: []string{"60123456789"},
+		})
+		assert.EqualError(t, err, "suppression list unavailable")
+		assert.False(t, called)
+		assert.Nil(t, output)
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+)
+
+// SendTemplated send personalized SMS to each recipient, rendering the template with the recipient's data.
+// Recipients with identical rendered messages are sent together in a single send SMS request, and the language type
+// of each rendered message is detected. Missing template data fails the rendering of the recipient.
+//
+// The output holds the result of each recipient, in the order of the input's Recipients. Recipients failing to render
+// or rejected when sending are reported with a per-recipient error, while an error is only returned when the input is invalid.
+func (c *Client) SendTemplated(input *SendTemplatedInput) (*SendTemplatedOutput, error) {
+	return c.SendTemplatedWithContext(context.Background(), input)
+}
+
+// SendTemplatedWithContext same as SendTemplated, with the requests bound to the context provided.
+func (c *Client) SendTemplatedWithContext(ctx context.Context, input *SendTemplatedInput) (*SendTemplatedOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("message").Option("missingkey=error").Parse(input.Template)
+	if err != nil {
+		return nil, owerr.NewValidationError("SendTemplatedInput", "Template", fmt.Sprintf("Template is invalid: %v", err))
+	}
+
+	type group struct {
+		message  string
+		mobileNo []string
+		indexes  []int
+	}
+	var (
+		output = &SendTemplatedOutput{Results: make([]SendSMSResult, len(input.Recipients))}
+		groups []*group
+		byMsg  = make(map[string]*group)
+	)
+	for i, recipient := range input.Recipients {
+		output.Results[i].MobileNo = recipient.MobileNo
+
+		buf := new(bytes.Buffer)
+		if err := tmpl.Execute(buf, recipient.Data); err != nil {
+			output.Results[i].Err = owerr.NewValidationError("SendTemplatedInput", "Recipients", fmt.Sprintf("Recipients[%d] failed to render: %v", i, err))
+			continue
+		}
+
+		message := buf.String()
+		g, ok := byMsg[message]
+		if !ok {
+			g = &group{message: message}
+			byMsg[message] = g
+			groups = append(groups, g)
+		}
+		g.mobileNo = append(g.mobileNo, recipient.MobileNo)
+		g.indexes = append(g.indexes, i)
+	}
+
+	for _, g := range groups {
+		smsOutput, _, err := c.SendSMSWithContext(ctx, &SendSMSInput{
+			Message:  g.message,
+			MobileNo: g.mobileNo,
+		})
+		for k, i := range g.indexes {
+			// The output reports the result of each recipient even along with an error, such as when every
+			// recipient was rejected before sending.
+			if smsOutput == nil || k >= len(smsOutput.Results) {
+				output.Results[i].Err = err
+				continue
+			}
+			output.Results[i].MTID = smsOutput.Results[k].MTID
+			output.Results[i].Err = smsOutput.Results[k].Err
+		}
+	}
+	return output, nil
+}
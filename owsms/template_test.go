@@ -0,0 +1,158 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package owsms_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendTemplated(t *testing.T) {
+	t.Run("With personalized messages", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			queries []url.Values
+		)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			queries = append(queries, r.URL.Query())
+			mtIDs := make([]string, 0)
+			for _, number := range strings.Split(r.URL.Query().Get("mobileno"), ",") {
+				mtIDs = append(mtIDs, "1"+number[len(number)-4:])
+			}
+			fmt.Fprintln(w, strings.Join(mtIDs, ","))
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL))
+
+		output, err := svc.SendTemplated(&owsms.SendTemplatedInput{
+			Template: "Hi {{.Name}}, your bill of RM{{.Amount}} is due.",
+			Recipients: []owsms.TemplateRecipient{
+				{MobileNo: "60120000001", Data: map[string]interface{}{"Name": "Ali", "Amount": 10}},
+				{MobileNo: "60120000002", Data: map[string]interface{}{"Name": "Mei", "Amount": 25}},
+				{MobileNo: "60120000003", Data: map[string]interface{}{"Name": "Ali", "Amount": 10}},
+				{MobileNo: "60120000004", Data: map[string]interface{}{"Name": "陈", "Amount": 25}},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []owsms.SendSMSResult{
+			{MobileNo: "60120000001", MTID: 10001},
+			{MobileNo: "60120000002", MTID: 10002},
+			{MobileNo: "60120000003", MTID: 10003},
+			{MobileNo: "60120000004", MTID: 10004},
+		}, output.Results)
+
+		assert.Len(t, queries, 3)
+		assert.Equal(t, "60120000001,60120000003", queries[0].Get("mobileno"))
+		assert.Equal(t, "Hi Ali, your bill of RM10 is due.", queries[0].Get("message"))
+		assert.Equal(t, string(owsms.LanguageTypeNormal), queries[0].Get("languagetype"))
+		assert.Equal(t, "60120000002", queries[1].Get("mobileno"))
+		assert.Equal(t, "Hi Mei, your bill of RM25 is due.", queries[1].Get("message"))
+		assert.Equal(t, "60120000004", queries[2].Get("mobileno"))
+		assert.Equal(t, string(owsms.LanguageTypeUnicode), queries[2].Get("languagetype"))
+		assert.Equal(t, owsms.EncodeUnicodeMessage("Hi 陈, your bill of RM25 is due."), queries[2].Get("message"))
+	})
+
+	t.Run("With recipient failing to render", func(t *testing.T) {
+		var calls int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			fmt.Fprintln(w, "145712468")
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL))
+
+		output, err := svc.SendTemplated(&owsms.SendTemplatedInput{
+			Template: "Hi {{.Name}}",
+			Recipients: []owsms.TemplateRecipient{
+				{MobileNo: "60120000001", Data: map[string]interface{}{"Name": "Ali"}},
+				{MobileNo: "60120000002", Data: map[string]interface{}{}},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, owsms.SendSMSResult{MobileNo: "60120000001", MTID: 145712468}, output.Results[0])
+		assert.Equal(t, "60120000002", output.Results[1].MobileNo)
+		vErr, ok := output.Results[1].Err.(owerr.ValidationError)
+		assert.True(t, ok)
+		assert.Equal(t, "Recipients", vErr.Field())
+		assert.Contains(t, vErr.Error(), "Recipients[1] failed to render")
+	})
+
+	t.Run("With gateway failure", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, "-100")
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(owsms.WithBaseURL(ts.URL))
+
+		output, err := svc.SendTemplated(&owsms.SendTemplatedInput{
+			Template: "Hi {{.}}",
+			Recipients: []owsms.TemplateRecipient{
+				{MobileNo: "60120000001", Data: "Ali"},
+				{MobileNo: "60120000002", Data: "Mei"},
+			},
+		})
+		assert.NoError(t, err)
+		for _, result := range output.Results {
+			owErr, ok := result.Err.(owerr.Error)
+			assert.True(t, ok)
+			assert.Equal(t, owerr.InvalidCredentials, owErr.Code())
+		}
+	})
+
+	t.Run("With every recipient rejected before sending", func(t *testing.T) {
+		var called bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer ts.Close()
+
+		svc := owsms.New(
+			owsms.WithBaseURL(ts.URL),
+			owsms.WithPhoneNormalization("MY"),
+			owsms.WithSuppressionList(owsms.NewMemorySuppressionList("60123456789")),
+		)
+
+		output, err := svc.SendTemplated(&owsms.SendTemplatedInput{
+			Template: "Hello World",
+			Recipients: []owsms.TemplateRecipient{
+				{MobileNo: "60123456789"},
+				{MobileNo: "123"},
+			},
+		})
+		assert.NoError(t, err)
+		assert.False(t, called)
+		assert.True(t, errors.Is(output.Results[0].Err, owerr.ErrRecipientSuppressed))
+		assert.EqualError(t, output.Results[1].Err, `SendSMSInput: Error: MobileNo "123" is invalid: invalid length for region MY`)
+	})
+
+	t.Run("With invalid template", func(t *testing.T) {
+		svc := owsms.New(owsms.WithBaseURL("http://localhost"))
+
+		output, err := svc.SendTemplated(&owsms.SendTemplatedInput{
+			Template:   "Hi {{.Name",
+			Recipients: []owsms.TemplateRecipient{{MobileNo: "60120000001"}},
+		})
+		vErr, ok := err.(owerr.ValidationError)
+		assert.True(t, ok)
+		assert.Equal(t, "Template", vErr.Field())
+		assert.Nil(t, output)
+	})
+}
@@ -5,7 +5,7 @@
 package owsms
 
 import (
-	"github.com/pkg/errors"
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
 )
 
 // LanguageType SMS language type type.
@@ -28,32 +28,102 @@ const (
 
 	// MTTransactionStatusTelcoDelivered message has been delivered to Telco.
 	MTTransactionStatusTelcoDelivered MTTransactionStatus = "telco_delivered"
+
+	// MTTransactionStatusFailed message has failed to be delivered.
+	MTTransactionStatusFailed MTTransactionStatus = "failed"
+
+	// MTTransactionStatusNotFound mobile terminating ID is invalid or not found.
+	MTTransactionStatusNotFound MTTransactionStatus = "not_found"
+
+	// MTTransactionStatusUnknown status code is unknown. Refer to the raw code for details.
+	MTTransactionStatusUnknown MTTransactionStatus = "unknown"
 )
 
+// Final reports whether the status is final, namely MTTransactionStatusSuccess, MTTransactionStatusFailed or MTTransactionStatusNotFound.
+func (s MTTransactionStatus) Final() bool {
+	return s == MTTransactionStatusSuccess || s == MTTransactionStatusFailed || s == MTTransactionStatusNotFound
+}
+
+// ParseTransactionStatus returns the mobile terminating transaction status matching the status code returned by the gateway.
+func ParseTransactionStatus(code int) MTTransactionStatus {
+	switch code {
+	case 0:
+		return MTTransactionStatusSuccess
+	case 100:
+		return MTTransactionStatusTelcoDelivered
+	case -100:
+		return MTTransactionStatusNotFound
+	case -200:
+		return MTTransactionStatusFailed
+	default:
+		return MTTransactionStatusUnknown
+	}
+}
+
 // SendSMSInput send SMS input structure.
 type SendSMSInput struct {
 	LanguageType LanguageType // Language Type of the SMS. Refer to LanguageType for details.
 	Message      string       // Content of the SMS.
 	MobileNo     []string     // Phone number of recipient. Phone number must include country code. For example: 6581234567.
+
+	IdempotencyKey string // Optional client-supplied key deduplicating repeated sends. Requires an IdempotencyStore, see WithIdempotencyStore.
 }
 
-// Validate validates send SMS input's values.
+// Validate validates send SMS input's values. An empty LanguageType is valid, as it is detected from the message.
+// Returns an owerr.ValidationError when a value is invalid.
 func (i *SendSMSInput) Validate() error {
 	if i.Message == "" {
-		return errors.New("SendSMSInput: Error: Message is required")
+		return owerr.NewValidationError("SendSMSInput", "Message", "Message is required")
 	}
-	if i.MobileNo == nil || len(i.MobileNo) <= 0 {
-		return errors.New("SendSMSInput: Error: MobileNo is required")
+	if len(i.MobileNo) <= 0 {
+		return owerr.NewValidationError("SendSMSInput", "MobileNo", "MobileNo is required")
 	}
-	if i.LanguageType != LanguageTypeNormal && i.LanguageType != LanguageTypeUnicode {
-		return errors.New("SendSMSInput: Error: LanguageType is invalid")
+	if i.LanguageType != "" && i.LanguageType != LanguageTypeNormal && i.LanguageType != LanguageTypeUnicode {
+		return owerr.NewValidationError("SendSMSInput", "LanguageType", "LanguageType is invalid")
 	}
 	return nil
 }
 
 // SendSMSOutput send SMS output structure.
 type SendSMSOutput struct {
-	MTIDs []int // Mobile terminating ID(s) from the send SMS result.
+	MTIDs   []int           // Mobile terminating ID(s) of the messages successfully sent.
+	Results []SendSMSResult // Result of each recipient, in the order of SendSMSInput.MobileNo.
+}
+
+// SendSMSResult send SMS result structure of a single recipient.
+type SendSMSResult struct {
+	MobileNo string // Phone number of the recipient, as provided in SendSMSInput.MobileNo.
+	MTID     int    // Mobile terminating ID of the message sent to the recipient. Zero when Err is set.
+	Err      error  // Error rejecting the recipient, either an owerr.Error returned by the gateway or an owerr.ValidationError.
+}
+
+// SendTemplatedInput send templated SMS input structure.
+type SendTemplatedInput struct {
+	Template   string              // text/template template of the SMS content, executed with the data of each recipient.
+	Recipients []TemplateRecipient // Recipients of the SMS along with their template data.
+}
+
+// TemplateRecipient templated SMS recipient structure.
+type TemplateRecipient struct {
+	MobileNo string      // Phone number of recipient. Phone number must include country code. For example: 6581234567.
+	Data     interface{} // Data the template is executed with for this recipient. For example: map[string]interface{}{"Name": "John"}.
+}
+
+// Validate validates send templated SMS input's values.
+// Returns an owerr.ValidationError when a value is invalid.
+func (i *SendTemplatedInput) Validate() error {
+	if i.Template == "" {
+		return owerr.NewValidationError("SendTemplatedInput", "Template", "Template is required")
+	}
+	if len(i.Recipients) <= 0 {
+		return owerr.NewValidationError("SendTemplatedInput", "Recipients", "Recipients is required")
+	}
+	return nil
+}
+
+// SendTemplatedOutput send templated SMS output structure.
+type SendTemplatedOutput struct {
+	Results []SendSMSResult // Result of each recipient, in the order of SendTemplatedInput.Recipients.
 }
 
 // CheckTransactionStatusInput check transaction input structure.
@@ -62,9 +132,10 @@ type CheckTransactionStatusInput struct {
 }
 
 // Validate validates check transaction status input's values.
+// Returns an owerr.ValidationError when a value is invalid.
 func (i *CheckTransactionStatusInput) Validate() error {
 	if i.MTID == 0 {
-		return errors.New("CheckTransactionStatusInput: Error: MTID is required")
+		return owerr.NewValidationError("CheckTransactionStatusInput", "MTID", "MTID is required")
 	}
 	return nil
 }
@@ -72,6 +143,24 @@ func (i *CheckTransactionStatusInput) Validate() error {
 // CheckTransactionStatusOutput check transaction output structure.
 type CheckTransactionStatusOutput struct {
 	Status MTTransactionStatus //Status of the mobile terminating transaction. Refer to MTTransactionStatus for more details.
+	Code   int                 // Raw status code returned by the gateway.
+}
+
+// TransactionStatusResult transaction status result structure of a mobile terminating ID, returned by CheckTransactionStatuses.
+type TransactionStatusResult struct {
+	MTID   int                 // Mobile terminating ID.
+	Status MTTransactionStatus // Status of the mobile terminating transaction. Empty when Err is set.
+	Code   int                 // Raw status code returned by the gateway.
+	Err    error               // Error of the lookup, such as a network error or an invalid MTID. Nil on success.
+}
+
+// InboundMessage mobile originating message structure, forwarded by the gateway when a mobile number sends
+// a message, such as a reply, to the account.
+type InboundMessage struct {
+	MOID         string       // Mobile originating ID assigned by the gateway, when forwarded.
+	MobileNo     string       // Mobile number of the sender.
+	Message      string       // Content of the message, decoded when forwarded as Unicode.
+	LanguageType LanguageType // Language type the message was forwarded as. Refer to LanguageType for more details.
 }
 
 // CheckCreditBalanceOutput check credit balance output structure.
@@ -5,8 +5,10 @@
 package owsms_test
 
 import (
+	"strconv"
 	"testing"
 
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
 	"github.com/junwen-k/onewaysms-sdk-go/owsms"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -27,6 +29,14 @@ func TestSendSMSInputValidate(t *testing.T) {
 			},
 			expected: nil,
 		},
+		{
+			desc: "With missing LanguageType",
+			input: &owsms.SendSMSInput{
+				Message:  "Hello World",
+				MobileNo: []string{"60123456789"},
+			},
+			expected: nil,
+		},
 		{
 			desc: "With invalid LanguageType",
 			input: &owsms.SendSMSInput{
@@ -60,6 +70,8 @@ func TestSendSMSInputValidate(t *testing.T) {
 			actual := test.input.Validate()
 			if actual != nil {
 				assert.EqualError(t, test.expected, actual.Error())
+				_, ok := actual.(owerr.ValidationError)
+				assert.True(t, ok)
 			} else {
 				assert.Equal(t, test.expected, actual)
 			}
@@ -93,9 +105,75 @@ func TestCheckTransactionStatusInputValidate(t *testing.T) {
 			actual := test.input.Validate()
 			if actual != nil {
 				assert.EqualError(t, test.expected, actual.Error())
+				_, ok := actual.(owerr.ValidationError)
+				assert.True(t, ok)
 			} else {
 				assert.Equal(t, test.expected, actual)
 			}
 		})
 	}
 }
+
+func TestSendTemplatedInputValidate(t *testing.T) {
+	tests := []struct {
+		desc     string
+		input    *owsms.SendTemplatedInput
+		expected error
+	}{
+		{
+			desc: "With valid values",
+			input: &owsms.SendTemplatedInput{
+				Template:   "Hi {{.Name}}",
+				Recipients: []owsms.TemplateRecipient{{MobileNo: "60123456789"}},
+			},
+			expected: nil,
+		},
+		{
+			desc: "With missing Template",
+			input: &owsms.SendTemplatedInput{
+				Recipients: []owsms.TemplateRecipient{{MobileNo: "60123456789"}},
+			},
+			expected: errors.New("SendTemplatedInput: Error: Template is required"),
+		},
+		{
+			desc: "With missing Recipients",
+			input: &owsms.SendTemplatedInput{
+				Template: "Hi {{.Name}}",
+			},
+			expected: errors.New("SendTemplatedInput: Error: Recipients is required"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			actual := test.input.Validate()
+			if actual != nil {
+				assert.EqualError(t, test.expected, actual.Error())
+				_, ok := actual.(owerr.ValidationError)
+				assert.True(t, ok)
+			} else {
+				assert.Equal(t, test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseTransactionStatus(t *testing.T) {
+	tests := []struct {
+		code     int
+		expected owsms.MTTransactionStatus
+		final    bool
+	}{
+		{code: 0, expected: owsms.MTTransactionStatusSuccess, final: true},
+		{code: 100, expected: owsms.MTTransactionStatusTelcoDelivered, final: false},
+		{code: -100, expected: owsms.MTTransactionStatusNotFound, final: true},
+		{code: -200, expected: owsms.MTTransactionStatusFailed, final: true},
+		{code: -999, expected: owsms.MTTransactionStatusUnknown, final: false},
+	}
+	for _, test := range tests {
+		t.Run(strconv.Itoa(test.code), func(t *testing.T) {
+			actual := owsms.ParseTransactionStatus(test.code)
+			assert.Equal(t, test.expected, actual)
+			assert.Equal(t, test.final, actual.Final())
+		})
+	}
+}
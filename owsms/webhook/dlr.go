@@ -0,0 +1,98 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+)
+
+// DeliveryReport delivery report structure, pushed by the gateway when the status of a mobile terminating
+// transaction changes.
+type DeliveryReport struct {
+	MTID     int                       // Mobile terminating ID returned by SendSMS.
+	Status   owsms.MTTransactionStatus // Status of the mobile terminating transaction.
+	Code     int                       // Raw status code pushed by the gateway.
+	MobileNo string                    // Mobile number of the recipient, when pushed by the gateway.
+}
+
+// DeliveryReportFunc callback dispatched for every valid delivery report. Returning an error responds with
+// 500 Internal Server Error, so that the gateway pushes the report again.
+type DeliveryReportFunc func(ctx context.Context, report *DeliveryReport) error
+
+// DeliveryReportHandler http.Handler receiving delivery report callbacks with the mtid, status and optional
+// mobileno parameters, from the query of GET requests or the form of POST requests.
+// Callbacks failing the secret or IP allowlist checks are rejected with 403 Forbidden, and callbacks with
+// missing or invalid parameters with 400 Bad Request.
+type DeliveryReportHandler struct {
+	guard    *guard
+	callback DeliveryReportFunc
+}
+
+// NewDeliveryReportHandler initializes a new delivery report handler dispatching to the callback,
+// with the checks configured by the options provided.
+func NewDeliveryReportHandler(callback DeliveryReportFunc, opts ...Option) (*DeliveryReportHandler, error) {
+	g, err := newGuard(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &DeliveryReportHandler{guard: g, callback: callback}, nil
+}
+
+// ServeHTTP handles a delivery report callback.
+func (h *DeliveryReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.guard.parseForm(w, r) {
+		return
+	}
+
+	report, err := ParseDeliveryReport(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.callback(r.Context(), report); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// ParseDeliveryReport parses the delivery report of the callback request, validating its parameters.
+// The error returned is an owerr.ValidationError.
+func ParseDeliveryReport(r *http.Request) (*DeliveryReport, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, owerr.NewValidationError("DeliveryReport", "Form", err.Error())
+	}
+
+	mtID := strings.TrimSpace(r.Form.Get("mtid"))
+	if mtID == "" {
+		return nil, owerr.NewValidationError("DeliveryReport", "MTID", "MTID is required")
+	}
+	id, err := strconv.Atoi(mtID)
+	if err != nil || id <= 0 {
+		return nil, owerr.NewValidationError("DeliveryReport", "MTID", "MTID must be a positive integer")
+	}
+
+	status := strings.TrimSpace(r.Form.Get("status"))
+	if status == "" {
+		return nil, owerr.NewValidationError("DeliveryReport", "Status", "Status is required")
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return nil, owerr.NewValidationError("DeliveryReport", "Status", "Status must be an integer")
+	}
+
+	return &DeliveryReport{
+		MTID:     id,
+		Status:   owsms.ParseTransactionStatus(code),
+		Code:     code,
+		MobileNo: strings.TrimSpace(r.Form.Get("mobileno")),
+	}, nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryReportHandler(t *testing.T) {
+	tests := []struct {
+		desc     string
+		method   string
+		query    string
+		expected *webhook.DeliveryReport
+		status   int
+		body     string
+	}{
+		{
+			desc:     "With success report",
+			method:   http.MethodGet,
+			query:    "mtid=145712470&status=0&mobileno=60123456789",
+			expected: &webhook.DeliveryReport{MTID: 145712470, Status: owsms.MTTransactionStatusSuccess, Code: 0, MobileNo: "60123456789"},
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "With failed report without mobileno",
+			method:   http.MethodGet,
+			query:    "mtid=145712470&status=-200",
+			expected: &webhook.DeliveryReport{MTID: 145712470, Status: owsms.MTTransactionStatusFailed, Code: -200},
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "With unknown status",
+			method:   http.MethodPost,
+			query:    "mtid=145712470&status=-999",
+			expected: &webhook.DeliveryReport{MTID: 145712470, Status: owsms.MTTransactionStatusUnknown, Code: -999},
+			status:   http.StatusOK,
+		},
+		{
+			desc:   "With missing mtid",
+			method: http.MethodGet,
+			query:  "status=0",
+			status: http.StatusBadRequest,
+			body:   "DeliveryReport: Error: MTID is required",
+		},
+		{
+			desc:   "With invalid mtid",
+			method: http.MethodGet,
+			query:  "mtid=abc&status=0",
+			status: http.StatusBadRequest,
+			body:   "DeliveryReport: Error: MTID must be a positive integer",
+		},
+		{
+			desc:   "With missing status",
+			method: http.MethodGet,
+			query:  "mtid=145712470",
+			status: http.StatusBadRequest,
+			body:   "DeliveryReport: Error: Status is required",
+		},
+		{
+			desc:   "With invalid status",
+			method: http.MethodGet,
+			query:  "mtid=145712470&status=delivered",
+			status: http.StatusBadRequest,
+			body:   "DeliveryReport: Error: Status must be an integer",
+		},
+		{
+			desc:   "With unsupported method",
+			method: http.MethodPut,
+			query:  "mtid=145712470&status=0",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var actual *webhook.DeliveryReport
+			h, err := webhook.NewDeliveryReportHandler(func(ctx context.Context, report *webhook.DeliveryReport) error {
+				actual = report
+				return nil
+			})
+			assert.NoError(t, err)
+
+			var r *http.Request
+			if test.method == http.MethodPost {
+				r = httptest.NewRequest(test.method, "/dlr", strings.NewReader(test.query))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				r = httptest.NewRequest(test.method, "/dlr?"+test.query, nil)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, test.status, w.Code)
+			assert.Equal(t, test.expected, actual)
+			if test.body != "" {
+				assert.Equal(t, test.body, strings.TrimSpace(w.Body.String()))
+			}
+		})
+	}
+
+	t.Run("With callback error", func(t *testing.T) {
+		h, err := webhook.NewDeliveryReportHandler(func(ctx context.Context, report *webhook.DeliveryReport) error {
+			return errors.New("database unavailable")
+		})
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dlr?mtid=145712470&status=0", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "database unavailable")
+	})
+
+	t.Run("With secret", func(t *testing.T) {
+		calls := 0
+		h, err := webhook.NewDeliveryReportHandler(func(ctx context.Context, report *webhook.DeliveryReport) error {
+			calls++
+			return nil
+		}, webhook.WithSecret("s3cret"))
+		assert.NoError(t, err)
+
+		query := url.Values{"mtid": {"145712470"}, "status": {"0"}}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dlr?"+query.Encode(), nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		query.Set("secret", "wrong")
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dlr?"+query.Encode(), nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		query.Set("secret", "s3cret")
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dlr?"+query.Encode(), nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		query.Del("secret")
+		r := httptest.NewRequest(http.MethodGet, "/dlr?"+query.Encode(), nil)
+		r.Header.Set(webhook.SecretHeader, "s3cret")
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		assert.Equal(t, 2, calls)
+	})
+}
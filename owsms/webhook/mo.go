@@ -0,0 +1,93 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owerr"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+)
+
+// InboundMessageFunc callback dispatched for every valid inbound message. Returning an error responds with
+// 500 Internal Server Error, so that the gateway forwards the message again.
+type InboundMessageFunc func(ctx context.Context, message *owsms.InboundMessage) error
+
+// InboundMessageHandler http.Handler receiving mobile originating message callbacks with the mobileno, message and
+// optional moid and languagetype parameters, from the query of GET requests or the form of POST requests.
+// Messages forwarded with the Unicode language type are decoded from their hexadecimal form.
+// Callbacks failing the secret or IP allowlist checks are rejected with 403 Forbidden, and callbacks with
+// missing or invalid parameters with 400 Bad Request.
+type InboundMessageHandler struct {
+	guard    *guard
+	callback InboundMessageFunc
+}
+
+// NewInboundMessageHandler initializes a new inbound message handler dispatching to the callback,
+// with the checks configured by the options provided.
+func NewInboundMessageHandler(callback InboundMessageFunc, opts ...Option) (*InboundMessageHandler, error) {
+	g, err := newGuard(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &InboundMessageHandler{guard: g, callback: callback}, nil
+}
+
+// ServeHTTP handles a mobile originating message callback.
+func (h *InboundMessageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.guard.parseForm(w, r) {
+		return
+	}
+
+	message, err := ParseInboundMessage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.callback(r.Context(), message); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// ParseInboundMessage parses the inbound message of the callback request, validating its parameters.
+// The language type defaults to LanguageTypeNormal. The error returned is an owerr.ValidationError.
+func ParseInboundMessage(r *http.Request) (*owsms.InboundMessage, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, owerr.NewValidationError("InboundMessage", "Form", err.Error())
+	}
+
+	mobileNo := strings.TrimSpace(r.Form.Get("mobileno"))
+	if mobileNo == "" {
+		return nil, owerr.NewValidationError("InboundMessage", "MobileNo", "MobileNo is required")
+	}
+	if _, ok := r.Form["message"]; !ok {
+		return nil, owerr.NewValidationError("InboundMessage", "Message", "Message is required")
+	}
+
+	message := &owsms.InboundMessage{
+		MOID:         strings.TrimSpace(r.Form.Get("moid")),
+		MobileNo:     mobileNo,
+		Message:      r.Form.Get("message"),
+		LanguageType: owsms.LanguageType(strings.TrimSpace(r.Form.Get("languagetype"))),
+	}
+	switch message.LanguageType {
+	case "":
+		message.LanguageType = owsms.LanguageTypeNormal
+	case owsms.LanguageTypeNormal:
+	case owsms.LanguageTypeUnicode:
+		decoded, err := owsms.DecodeUnicodeMessage(strings.TrimSpace(message.Message))
+		if err != nil {
+			return nil, owerr.NewValidationError("InboundMessage", "Message", "Message is not valid Unicode hexadecimal")
+		}
+		message.Message = decoded
+	default:
+		return nil, owerr.NewValidationError("InboundMessage", "LanguageType", "LanguageType is invalid")
+	}
+	return message, nil
+}
@@ -0,0 +1,138 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms"
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInboundMessageHandler(t *testing.T) {
+	tests := []struct {
+		desc     string
+		method   string
+		params   url.Values
+		expected *owsms.InboundMessage
+		status   int
+		body     string
+	}{
+		{
+			desc:     "With normal message",
+			method:   http.MethodGet,
+			params:   url.Values{"moid": {"8812"}, "mobileno": {"60123456789"}, "message": {"STOP"}, "languagetype": {"1"}},
+			expected: &owsms.InboundMessage{MOID: "8812", MobileNo: "60123456789", Message: "STOP", LanguageType: owsms.LanguageTypeNormal},
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "With default language type",
+			method:   http.MethodPost,
+			params:   url.Values{"mobileno": {"60123456789"}, "message": {"Yes, 2 tickets"}},
+			expected: &owsms.InboundMessage{MobileNo: "60123456789", Message: "Yes, 2 tickets", LanguageType: owsms.LanguageTypeNormal},
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "With unicode message",
+			method:   http.MethodGet,
+			params:   url.Values{"mobileno": {"60123456789"}, "message": {owsms.EncodeUnicodeMessage("你好 👋")}, "languagetype": {"2"}},
+			expected: &owsms.InboundMessage{MobileNo: "60123456789", Message: "你好 👋", LanguageType: owsms.LanguageTypeUnicode},
+			status:   http.StatusOK,
+		},
+		{
+			desc:   "With invalid unicode message",
+			method: http.MethodGet,
+			params: url.Values{"mobileno": {"60123456789"}, "message": {"4F6"}, "languagetype": {"2"}},
+			status: http.StatusBadRequest,
+			body:   "InboundMessage: Error: Message is not valid Unicode hexadecimal",
+		},
+		{
+			desc:   "With invalid language type",
+			method: http.MethodGet,
+			params: url.Values{"mobileno": {"60123456789"}, "message": {"Hi"}, "languagetype": {"3"}},
+			status: http.StatusBadRequest,
+			body:   "InboundMessage: Error: LanguageType is invalid",
+		},
+		{
+			desc:   "With missing mobileno",
+			method: http.MethodGet,
+			params: url.Values{"message": {"STOP"}},
+			status: http.StatusBadRequest,
+			body:   "InboundMessage: Error: MobileNo is required",
+		},
+		{
+			desc:   "With missing message",
+			method: http.MethodGet,
+			params: url.Values{"mobileno": {"60123456789"}},
+			status: http.StatusBadRequest,
+			body:   "InboundMessage: Error: Message is required",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var actual *owsms.InboundMessage
+			h, err := webhook.NewInboundMessageHandler(func(ctx context.Context, message *owsms.InboundMessage) error {
+				actual = message
+				return nil
+			})
+			assert.NoError(t, err)
+
+			ts := httptest.NewServer(h)
+			defer ts.Close()
+
+			var resp *http.Response
+			if test.method == http.MethodPost {
+				resp, err = http.PostForm(ts.URL+"/mo", test.params)
+			} else {
+				resp, err = http.Get(ts.URL + "/mo?" + test.params.Encode())
+			}
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.status, resp.StatusCode)
+			assert.Equal(t, test.expected, actual)
+			if test.body != "" {
+				assert.Equal(t, test.body, strings.TrimSpace(string(body)))
+			}
+		})
+	}
+
+	t.Run("With callback error", func(t *testing.T) {
+		h, err := webhook.NewInboundMessageHandler(func(ctx context.Context, message *owsms.InboundMessage) error {
+			return errors.New("queue unavailable")
+		})
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/mo?mobileno=60123456789&message=STOP", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("With secret", func(t *testing.T) {
+		h, err := webhook.NewInboundMessageHandler(func(ctx context.Context, message *owsms.InboundMessage) error {
+			return nil
+		}, webhook.WithSecret("s3cret"))
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/mo?mobileno=60123456789&message=STOP&secret=wrong", nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/mo?mobileno=60123456789&message=STOP&secret=s3cret", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
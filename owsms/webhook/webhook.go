@@ -0,0 +1,136 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package webhook provides HTTP handlers receiving the callbacks pushed by the OneWaySMS API gateway,
+// namely delivery reports and inbound mobile originating messages, and dispatching them to user callbacks.
+package webhook
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SecretParam name of the query or form parameter holding the shared secret.
+	SecretParam = "secret"
+
+	// SecretHeader name of the header holding the shared secret, checked when the parameter is absent.
+	SecretHeader = "X-Webhook-Secret"
+)
+
+// Option configures the checks of a handler.
+type Option func(*guard) error
+
+// WithSecret requires callbacks to carry the shared secret in the SecretParam parameter or the SecretHeader header,
+// usually by appending it to the callback URL configured at the gateway. The secret is compared in constant time.
+func WithSecret(secret string) Option {
+	return func(g *guard) error {
+		if secret == "" {
+			return errors.New("webhook: Error: secret is required")
+		}
+		g.secret = []byte(secret)
+		return nil
+	}
+}
+
+// WithAllowedIPs only accepts callbacks whose remote address matches one of the IP addresses or CIDR ranges provided.
+// The remote address is taken from http.Request.RemoteAddr, forwarded headers are not trusted. When running behind
+// a proxy, rewrite RemoteAddr with a trusted middleware before the handler.
+func WithAllowedIPs(ips ...string) Option {
+	return func(g *guard) error {
+		for _, ip := range ips {
+			if !strings.Contains(ip, "/") {
+				parsed := net.ParseIP(ip)
+				if parsed == nil {
+					return errors.Errorf("webhook: Error: allowed IP %q is invalid", ip)
+				}
+				bits := 8 * net.IPv4len
+				if parsed.To4() == nil {
+					bits = 8 * net.IPv6len
+				}
+				g.allowed = append(g.allowed, &net.IPNet{IP: parsed, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+			_, network, err := net.ParseCIDR(ip)
+			if err != nil {
+				return errors.Errorf("webhook: Error: allowed IP range %q is invalid", ip)
+			}
+			g.allowed = append(g.allowed, network)
+		}
+		return nil
+	}
+}
+
+// guard checks the origin of callbacks.
+type guard struct {
+	secret  []byte
+	allowed []*net.IPNet
+}
+
+func newGuard(opts []Option) (*guard, error) {
+	g := &guard{}
+	for _, opt := range opts {
+		if err := opt(g); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// check reports whether the callback passes the secret and IP allowlist checks. The form must be parsed.
+func (g *guard) check(r *http.Request) bool {
+	if len(g.allowed) > 0 && !g.allowedIP(r.RemoteAddr) {
+		return false
+	}
+	if len(g.secret) > 0 {
+		secret := r.Form.Get(SecretParam)
+		if secret == "" {
+			secret = r.Header.Get(SecretHeader)
+		}
+		if subtle.ConstantTimeCompare([]byte(secret), g.secret) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *guard) allowedIP(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range g.allowed {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseForm parses the callback parameters, from the query of GET requests or the form of POST requests,
+// responding with an error and returning false when the request is rejected.
+func (g *guard) parseForm(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if !g.check(r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+// Copyright (c) KwanJunWen
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package webhook_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/junwen-k/onewaysms-sdk-go/owsms/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAllowedIPs(t *testing.T) {
+	tests := []struct {
+		desc       string
+		remoteAddr string
+		status     int
+	}{
+		{desc: "With allowed IP", remoteAddr: "203.0.113.7:51234", status: http.StatusOK},
+		{desc: "With IP in allowed range", remoteAddr: "198.51.100.42:51234", status: http.StatusOK},
+		{desc: "With allowed IPv6", remoteAddr: "[2001:db8::1]:51234", status: http.StatusOK},
+		{desc: "With disallowed IP", remoteAddr: "192.0.2.1:51234", status: http.StatusForbidden},
+		{desc: "With invalid remote address", remoteAddr: "unknown", status: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			h, err := webhook.NewDeliveryReportHandler(func(ctx context.Context, report *webhook.DeliveryReport) error {
+				return nil
+			}, webhook.WithAllowedIPs("203.0.113.7", "198.51.100.0/24", "2001:db8::1"))
+			assert.NoError(t, err)
+
+			r := httptest.NewRequest(http.MethodGet, "/dlr?mtid=145712470&status=0", nil)
+			r.RemoteAddr = test.remoteAddr
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			assert.Equal(t, test.status, w.Code)
+		})
+	}
+
+	t.Run("With invalid IP", func(t *testing.T) {
+		_, err := webhook.NewDeliveryReportHandler(nil, webhook.WithAllowedIPs("203.0.113"))
+		assert.EqualError(t, err, `webhook: Error: allowed IP "203.0.113" is invalid`)
+
+		_, err = webhook.NewDeliveryReportHandler(nil, webhook.WithAllowedIPs("198.51.100.0/33"))
+		assert.EqualError(t, err, `webhook: Error: allowed IP range "198.51.100.0/33" is invalid`)
+	})
+}
+
+func TestWithSecret(t *testing.T) {
+	_, err := webhook.NewDeliveryReportHandler(nil, webhook.WithSecret(""))
+	assert.EqualError(t, err, "webhook: Error: secret is required")
+}